@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// AuditRecord describes an outbound request for audit trails
+type AuditRecord struct {
+	// Time is when the request started
+	Time   time.Time
+	Method string
+	URL    string
+	// StatusCode is the status of the last response, 0 if there was none
+	StatusCode int
+	// Duration covers all attempts and the delays between them
+	Duration  time.Duration
+	Caller    string
+	RequestID string
+	// BytesSent is the size of the encoded request body, -1 if it was streamed
+	BytesSent int64
+	// BytesReceived is the size of the response body, -1 if it was streamed
+	BytesReceived int64
+	// Err is why the request failed, if it did
+	Err error
+}
+
+// AuditSink receives a record of every request the client sends. Audit is
+// called when the request ends, so it should hand the record off quickly.
+type AuditSink interface {
+	Audit(AuditRecord)
+}
+
+// AuditFunc adapts an ordinary function to the AuditSink interface
+type AuditFunc func(AuditRecord)
+
+func (f AuditFunc) Audit(r AuditRecord) {
+	f(r)
+}
+
+type callerKey struct{}
+
+// ContextWithCaller returns a context carrying the identity of the caller on
+// whose behalf requests made with it are sent, for audit records
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller identity carried by ctx, if any
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// SetAuditSink sends a record of every request to s; nil disables it.
+// Requests answered by a hook with Respond never go out and aren't recorded.
+func (c *Client) SetAuditSink(s AuditSink) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.audit = s
+	return c
+}
+
+// recordAudit sends the audit record of a finished request
+func (c *Client) recordAudit(muxReq *Request, body *requestBody, muxResp *Response, err error, start time.Time) {
+	rec := AuditRecord{
+		Time:      start,
+		Method:    muxReq.Method,
+		URL:       muxReq.URL,
+		Duration:  time.Since(start),
+		Caller:    CallerFromContext(muxReq.Context),
+		RequestID: muxReq.ID,
+		Err:       err,
+	}
+	switch {
+	case body == nil:
+	case body.data != nil:
+		rec.BytesSent = int64(len(body.data))
+	default:
+		rec.BytesSent = -1
+	}
+	if muxResp != nil {
+		rec.StatusCode = muxResp.StatusCode
+		rec.BytesReceived = int64(len(muxResp.Body))
+		if muxResp.Stream != nil {
+			rec.BytesReceived = -1
+		}
+	}
+
+	perr := callHook(func(r AuditRecord) error {
+		c.audit.Audit(r)
+		return nil
+	}, rec)
+	var panicErr *PanicError
+	if errors.As(perr, &panicErr) {
+		c.logf(muxReq.Context, slog.LevelError, "Audit sink panicked",
+			requestAttrs(muxReq, slog.Any("panic", panicErr.Value), slog.String("stack", string(panicErr.Stack))),
+			"Audit sink panicked: %v\n%s", panicErr.Value, panicErr.Stack)
+	}
+}
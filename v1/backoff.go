@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes the delay before a retry. attempt is the zero-based
+// index of the attempt that just failed and prev is the previous delay
+// (zero before the first retry).
+type Backoff interface {
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same delay before every retry
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+func (b ConstantBackoff) Next(int, time.Duration) time.Duration {
+	return b.Delay
+}
+
+// ExponentialBackoff waits Base * 2^attempt, capped at Max when Max > 0
+type ExponentialBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b ExponentialBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	return exponential(b.Base, b.Max, attempt)
+}
+
+// FullJitterBackoff waits a random delay in [0, Base * 2^attempt], capped at Max when Max > 0
+type FullJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b FullJitterBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	return jitter(0, exponential(b.Base, b.Max, attempt))
+}
+
+// DecorrelatedJitterBackoff waits a random delay in [Base, prev * 3],
+// capped at Max when Max > 0
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b DecorrelatedJitterBackoff) Next(_ int, prev time.Duration) time.Duration {
+	upper := b.Base
+	if prev > 0 && prev <= math.MaxInt64/3 {
+		upper = max(b.Base, prev*3)
+	}
+	d := jitter(b.Base, upper)
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+func exponential(base, limit time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt; i++ {
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if limit > 0 && d > limit {
+		d = limit
+	}
+	return d
+}
+
+// jitter returns a random duration in [lo, hi]
+func jitter(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(rand.Int64N(int64(hi-lo)+1))
+}
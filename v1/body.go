@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	octetStream     = "application/octet-stream"
+	formContentType = "application/x-www-form-urlencoded"
+)
+
+// errBodyConsumed is returned when a single-use body would have to be sent again
+var errBodyConsumed = errors.New("request body can't be replayed")
+
+// BodyFunc returns a fresh request body for every attempt, like http.Request.GetBody.
+// Use it to stream large uploads that still survive retries and redirects.
+type BodyFunc func() (io.ReadCloser, error)
+
+// preparedBody is the body of a request passed to Client.Do, replayed with
+// its GetBody func when it has one
+type preparedBody struct {
+	req *http.Request
+}
+
+// requestBody produces the body of each attempt. Plain values are encoded
+// once, as form data for url.Values and structs with form tags, with the
+// encoder registered for the Content-Type or as JSON otherwise; readers are
+// streamed as they are, rewound between attempts when they can seek, and sent
+// only once otherwise.
+type requestBody struct {
+	data    []byte
+	reader  io.Reader
+	offset  int64
+	fn      BodyFunc
+	size    int64
+	used    bool
+	oneShot bool
+	// gzip compresses streamed bodies on the fly
+	gzip bool
+
+	// contentType is sent unless the request sets its own; with fixedType it
+	// always is, because it carries parameters the body depends on
+	contentType string
+	fixedType   bool
+}
+
+func newRequestBody(body any, enc Encoder) (*requestBody, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case *Multipart:
+		return &requestBody{fn: b.open, oneShot: !b.replayable(), contentType: b.ContentType(), fixedType: true}, nil
+	case RawBody:
+		// nil data would mean no body source at all
+		if b == nil {
+			b = RawBody{}
+		}
+		return &requestBody{data: b, contentType: octetStream}, nil
+	case url.Values:
+		return &requestBody{data: []byte(b.Encode()), contentType: formContentType}, nil
+	case BodyFunc:
+		return &requestBody{fn: b, contentType: octetStream}, nil
+	case func() (io.ReadCloser, error):
+		return &requestBody{fn: b, contentType: octetStream}, nil
+	case preparedBody:
+		rb := &requestBody{fn: b.req.GetBody, size: max(b.req.ContentLength, 0)}
+		if rb.fn == nil {
+			rb.reader = b.req.Body
+		}
+		return rb, nil
+	case io.Reader:
+		rb := &requestBody{reader: b, contentType: octetStream}
+		if s, ok := b.(io.Seeker); ok {
+			offset, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to seek body: %w", err)
+			}
+			rb.offset = offset
+		}
+		return rb, nil
+	}
+	if hasTag(body, "form") {
+		form, err := encodeValues(body, "form")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode form body: %w", err)
+		}
+		return &requestBody{data: []byte(form.Encode()), contentType: formContentType}, nil
+	}
+	marshal, contentType := enc, ""
+	if marshal == nil {
+		marshal, contentType = json.Marshal, "application/json"
+	}
+	data, err := marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return &requestBody{data: data, contentType: contentType}, nil
+}
+
+// replayable reports whether the body can be sent more than once
+func (b *requestBody) replayable() bool {
+	if b == nil || b.data != nil {
+		return true
+	}
+	if b.fn != nil {
+		return !b.oneShot
+	}
+	_, ok := b.reader.(io.Seeker)
+	return ok
+}
+
+// getBody returns a GetBody func for following redirects, or nil for readers
+func (b *requestBody) getBody() func() (io.ReadCloser, error) {
+	switch {
+	case b.data != nil:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b.data)), nil
+		}
+	case b.fn != nil && !b.oneShot:
+		if b.gzip {
+			return func() (io.ReadCloser, error) {
+				rc, err := b.fn()
+				if err != nil {
+					return nil, err
+				}
+				return gzipStream(rc), nil
+			}
+		}
+		return b.fn
+	}
+	return nil
+}
+
+// open returns the body for the next attempt and its length, or 0 if unknown
+func (b *requestBody) open() (io.ReadCloser, int64, error) {
+	rc, size, err := b.source()
+	if err != nil || !b.gzip {
+		return rc, size, err
+	}
+	return gzipStream(rc), 0, nil
+}
+
+// source returns the uncompressed body for the next attempt. Readers are
+// never closed by the transport, they belong to the caller.
+func (b *requestBody) source() (io.ReadCloser, int64, error) {
+	switch {
+	case b.data != nil:
+		return io.NopCloser(bytes.NewReader(b.data)), int64(len(b.data)), nil
+	case b.fn != nil:
+		rc, err := b.fn()
+		return rc, b.size, err
+	}
+
+	if s, ok := b.reader.(io.Seeker); ok {
+		end, err := s.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = s.Seek(b.offset, io.SeekStart)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to rewind body: %w", err)
+		}
+		return io.NopCloser(b.reader), end - b.offset, nil
+	}
+	if b.used {
+		return nil, 0, errBodyConsumed
+	}
+	b.used = true
+	size := b.size
+	if l, ok := b.reader.(interface{ Len() int }); ok {
+		size = int64(l.Len())
+	}
+	return io.NopCloser(b.reader), size, nil
+}
+
+// WithUploadProgress reports how many bytes of the request body were sent, at
+// most every 100ms and once more when done. total is -1 if the size is unknown.
+func WithUploadProgress(fn func(sent, total int64)) RequestOption {
+	return func(o *requestOptions) {
+		o.uploadProgress = fn
+	}
+}
+
+// progressReader counts the bytes read from a request body
+type progressReader struct {
+	io.ReadCloser
+	fn    func(sent, total int64)
+	total int64
+	sent  int64
+	// reported and last are the count and time of the last callback
+	reported int64
+	last     time.Time
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.sent += int64(n)
+	if r.sent == r.reported {
+		return n, err
+	}
+	if now := time.Now(); err == io.EOF || r.sent == r.total || now.Sub(r.last) >= progressInterval {
+		r.last, r.reported = now, r.sent
+		r.fn(r.sent, r.total)
+	}
+	return n, err
+}
@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker rejects a request
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of the circuit for a single host
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerSettings configures a CircuitBreaker. Zero values fall back to defaults.
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit (default 5)
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive half-open successes that closes it again (default 1)
+	SuccessThreshold int
+	// CoolDown is how long the circuit stays open before letting probes through (default 30s)
+	CoolDown time.Duration
+	// HalfOpenMaxRequests is the number of concurrent probes allowed while half-open (default 1)
+	HalfOpenMaxRequests int
+	// IsFailure classifies an attempt; by default network errors and 5xx responses count
+	IsFailure func(resp *Response, err error) bool
+	// OnStateChange is called on every state transition
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// CircuitBreaker tracks upstream health per host and fails fast while a host is down
+type CircuitBreaker struct {
+	settings BreakerSettings
+	mu       sync.Mutex
+	hosts    map[string]*circuit
+}
+
+type circuit struct {
+	state     BreakerState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+type transition struct {
+	host     string
+	from, to BreakerState
+}
+
+// NewCircuitBreaker creates a per-host circuit breaker
+func NewCircuitBreaker(s BreakerSettings) *CircuitBreaker {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+	if s.SuccessThreshold <= 0 {
+		s.SuccessThreshold = 1
+	}
+	if s.CoolDown <= 0 {
+		s.CoolDown = 30 * time.Second
+	}
+	if s.HalfOpenMaxRequests <= 0 {
+		s.HalfOpenMaxRequests = 1
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = hostFailure
+	}
+	return &CircuitBreaker{
+		settings: s,
+		hosts:    make(map[string]*circuit),
+	}
+}
+
+// State returns the current state of the circuit for host
+func (b *CircuitBreaker) State(host string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb, ok := b.hosts[host]
+	if !ok {
+		return StateClosed
+	}
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= b.settings.CoolDown {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// allow reports whether a request to host may proceed
+func (b *CircuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	cb := b.circuit(host)
+	var t *transition
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= b.settings.CoolDown {
+		t = b.setState(host, cb, StateHalfOpen)
+	}
+	var err error
+	switch cb.state {
+	case StateOpen:
+		err = fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+	case StateHalfOpen:
+		if cb.probes >= b.settings.HalfOpenMaxRequests {
+			err = fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+		} else {
+			cb.probes++
+		}
+	}
+	b.mu.Unlock()
+	b.notify(t)
+	return err
+}
+
+// record feeds the outcome of an allowed attempt to host back into the breaker
+func (b *CircuitBreaker) record(host string, resp *Response, err error) {
+	// an attempt cancelled by the caller tells nothing about the host
+	if errors.Is(err, context.Canceled) {
+		b.release(host)
+		return
+	}
+	failed := b.settings.IsFailure(resp, err)
+
+	b.mu.Lock()
+	cb := b.circuit(host)
+	var t *transition
+	switch cb.state {
+	case StateClosed:
+		if !failed {
+			cb.failures = 0
+		} else if cb.failures++; cb.failures >= b.settings.FailureThreshold {
+			t = b.setState(host, cb, StateOpen)
+		}
+	case StateHalfOpen:
+		cb.probes = max(cb.probes-1, 0)
+		if failed {
+			t = b.setState(host, cb, StateOpen)
+		} else if cb.successes++; cb.successes >= b.settings.SuccessThreshold {
+			t = b.setState(host, cb, StateClosed)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(t)
+}
+
+// release frees the probe slot of an attempt to host without an outcome
+func (b *CircuitBreaker) release(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cb := b.circuit(host); cb.state == StateHalfOpen {
+		cb.probes = max(cb.probes-1, 0)
+	}
+}
+
+func (b *CircuitBreaker) circuit(host string) *circuit {
+	cb, ok := b.hosts[host]
+	if !ok {
+		cb = &circuit{}
+		b.hosts[host] = cb
+	}
+	return cb
+}
+
+// setState moves cb to state and resets its counters; callers hold b.mu
+func (b *CircuitBreaker) setState(host string, cb *circuit, state BreakerState) *transition {
+	t := &transition{host: host, from: cb.state, to: state}
+	cb.state = state
+	cb.failures, cb.successes, cb.probes = 0, 0, 0
+	if state == StateOpen {
+		cb.openedAt = time.Now()
+	}
+	return t
+}
+
+func (b *CircuitBreaker) notify(t *transition) {
+	if t != nil && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(t.host, t.from, t.to)
+	}
+}
+
+// hostFailure reports whether an attempt indicates the host itself is unhealthy:
+// a network error or a 5xx response
+func hostFailure(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp != nil && resp.StatusCode >= 500
+}
@@ -0,0 +1,40 @@
+package v1
+
+import "sync"
+
+// RetryBudget caps retries across all requests of a client, so a burst of
+// failures cannot multiply load on an upstream. Every request deposits
+// ratio tokens and every retry withdraws one; the balance never exceeds
+// the reserve the budget starts with.
+type RetryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	max    float64
+	tokens float64
+}
+
+// NewRetryBudget creates a budget allowing ratio retries per request on
+// average, with an initial reserve of retries available for bursts
+func NewRetryBudget(ratio float64, reserve int) *RetryBudget {
+	return &RetryBudget{
+		ratio:  ratio,
+		max:    float64(max(reserve, 1)),
+		tokens: float64(max(reserve, 1)),
+	}
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.max)
+}
+
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
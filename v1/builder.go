@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// RequestBuilder configures a single request step by step, see Client.R
+type RequestBuilder struct {
+	c       *Client
+	ctx     context.Context
+	headers map[string]string
+	body    any
+	result  any
+	errOut  any
+	opts    []RequestOption
+}
+
+// R starts building a request:
+//
+//	resp, err := client.R().
+//		SetPathParam("id", id).
+//		SetQueryParam("expand", "owner").
+//		SetResult(&repo).
+//		Get("/repos/{id}")
+func (c *Client) R() *RequestBuilder {
+	return &RequestBuilder{c: c, headers: make(map[string]string)}
+}
+
+// SetContext sets the context of the request; without one the client timeout applies
+func (r *RequestBuilder) SetContext(ctx context.Context) *RequestBuilder {
+	r.ctx = ctx
+	return r
+}
+
+func (r *RequestBuilder) SetHeader(key, value string) *RequestBuilder {
+	r.headers[key] = value
+	return r
+}
+
+func (r *RequestBuilder) SetHeaders(headers map[string]string) *RequestBuilder {
+	for k, v := range headers {
+		r.headers[k] = v
+	}
+	return r
+}
+
+// SetCookie sends a cookie with the request, see WithCookie
+func (r *RequestBuilder) SetCookie(cookie *http.Cookie) *RequestBuilder {
+	return r.With(WithCookie(cookie))
+}
+
+func (r *RequestBuilder) SetQueryParam(key, value string) *RequestBuilder {
+	return r.With(WithQueryParam(key, value))
+}
+
+func (r *RequestBuilder) SetQueryParams(params map[string]string) *RequestBuilder {
+	for k, v := range params {
+		r.With(WithQueryParam(k, v))
+	}
+	return r
+}
+
+// SetQuery encodes the fields of a tagged struct as query parameters, see WithQuery
+func (r *RequestBuilder) SetQuery(v any) *RequestBuilder {
+	return r.With(WithQuery(v))
+}
+
+func (r *RequestBuilder) SetPathParam(name, value string) *RequestBuilder {
+	return r.With(WithPathParam(name, value))
+}
+
+func (r *RequestBuilder) SetPathParams(params map[string]string) *RequestBuilder {
+	return r.With(WithPathParams(params))
+}
+
+// SetBody sets the request body, encoded like the body argument of DoRequest
+func (r *RequestBuilder) SetBody(body any) *RequestBuilder {
+	r.body = body
+	return r
+}
+
+// SetResult sets where a successful response is decoded to
+func (r *RequestBuilder) SetResult(out any) *RequestBuilder {
+	r.result = out
+	return r
+}
+
+// SetError sets where the body of an error response is decoded to; the
+// request still returns the *HTTPError
+func (r *RequestBuilder) SetError(out any) *RequestBuilder {
+	r.errOut = out
+	return r
+}
+
+// With adds request options
+func (r *RequestBuilder) With(opts ...RequestOption) *RequestBuilder {
+	r.opts = append(r.opts, opts...)
+	return r
+}
+
+func (r *RequestBuilder) Get(url string) (*Response, error) {
+	return r.Execute(http.MethodGet, url)
+}
+
+func (r *RequestBuilder) Post(url string) (*Response, error) {
+	return r.Execute(http.MethodPost, url)
+}
+
+func (r *RequestBuilder) Put(url string) (*Response, error) {
+	return r.Execute(http.MethodPut, url)
+}
+
+func (r *RequestBuilder) Patch(url string) (*Response, error) {
+	return r.Execute(http.MethodPatch, url)
+}
+
+func (r *RequestBuilder) Delete(url string) (*Response, error) {
+	return r.Execute(http.MethodDelete, url)
+}
+
+func (r *RequestBuilder) Head(url string) (*Response, error) {
+	return r.Execute(http.MethodHead, url)
+}
+
+func (r *RequestBuilder) Options(url string) (*Response, error) {
+	return r.Execute(http.MethodOptions, url)
+}
+
+// Execute sends the request with the given method and decodes the response
+// into the result or error target
+func (r *RequestBuilder) Execute(method, url string) (*Response, error) {
+	_, resp, err := r.c.do(r.ctx, method, url, r.body, r.headers, r.opts)
+	if err != nil {
+		var httpErr *HTTPError
+		if r.errOut != nil && resp != nil && errors.As(err, &httpErr) {
+			r.c.decode(resp, r.errOut)
+		}
+		return resp, err
+	}
+	return resp, r.c.decode(resp, r.result)
+}
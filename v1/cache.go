@@ -0,0 +1,449 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheStatusHeader is set on responses served by a ResponseCache: "hit" when
+// the stored response was fresh, "revalidated" when the server confirmed it
+const CacheStatusHeader = "X-Muxet-Cache"
+
+// ResponseCache caches responses to GET requests as a private cache following
+// RFC 7234. Fresh responses are served without a request; stale ones, and
+// those marked no-cache, are revalidated with a conditional request using
+// their ETag or Last-Modified validators. Successful unsafe requests
+// invalidate the cached response of their URL. Register it with
+// client.Use(cache.Middleware()).
+type ResponseCache struct {
+	store Cache
+	// alwaysRevalidate ignores freshness and only keeps responses with
+	// validators, see NewETagCache
+	alwaysRevalidate bool
+}
+
+// cacheEntry is a stored response, encoded as JSON in the store
+type cacheEntry struct {
+	Status     string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Vary holds the request headers the response varies on
+	Vary         map[string]string
+	RequestTime  time.Time
+	ResponseTime time.Time
+	// TTL replaces the freshness lifetime given by the server, see WithCacheTTL
+	TTL time.Duration `json:",omitempty"`
+}
+
+// cacheOptions are the per-request cache settings, passed to the middleware
+// in the request context
+type cacheOptions struct {
+	bypass  bool
+	refresh bool
+	ttl     time.Duration
+}
+
+type cacheOptionsKey struct{}
+
+// WithCacheBypass sends the request past the ResponseCache: no cached
+// response is served and the response isn't stored
+func WithCacheBypass() RequestOption {
+	return func(o *requestOptions) {
+		o.cache.bypass = true
+	}
+}
+
+// WithForceRefresh ignores the cached response and fetches a new one, which
+// replaces it in the ResponseCache
+func WithForceRefresh() RequestOption {
+	return func(o *requestOptions) {
+		o.cache.refresh = true
+	}
+}
+
+// WithCacheTTL keeps the response fresh for d whatever the server says, and
+// serves a cached response younger than d. Responses without caching headers
+// are stored too, but no-store is still honored.
+func WithCacheTTL(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.cache.ttl = d
+	}
+}
+
+// NewResponseCache returns a cache keeping responses in store, or in an LRU
+// cache of DefaultCacheEntries if store is nil
+func NewResponseCache(store Cache) *ResponseCache {
+	if store == nil {
+		store = NewLRUCache(DefaultCacheEntries)
+	}
+	return &ResponseCache{store: store}
+}
+
+// Middleware serves and stores responses
+func (rc *ResponseCache) Middleware() Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return rc.do(next, req)
+		})
+	}
+}
+
+func (rc *ResponseCache) do(next HTTPDoer, req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+	if req.Method != http.MethodGet {
+		resp, err := next.Do(req)
+		if err == nil && !isSafeMethod(req.Method) && resp.StatusCode < 400 {
+			rc.invalidate(req, resp)
+		}
+		return resp, err
+	}
+	// requests with their own validators or ranges are the caller's business
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" || req.Header.Get("Range") != "" {
+		return next.Do(req)
+	}
+
+	opts, _ := req.Context().Value(cacheOptionsKey{}).(cacheOptions)
+	if opts.bypass {
+		return next.Do(req)
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	_, noStore := reqCC["no-store"]
+	var entry *cacheEntry
+	if !opts.refresh {
+		entry = rc.lookup(key, req)
+	}
+	if entry != nil && opts.ttl > 0 {
+		entry.TTL = opts.ttl
+	}
+	now := time.Now()
+	if entry != nil && !noStore && (!rc.alwaysRevalidate || entry.TTL > 0) && entry.fresh(reqCC, now) {
+		return entry.response(req, now, "hit"), nil
+	}
+	if _, ok := reqCC["only-if-cached"]; ok {
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	sent := req
+	if entry != nil && !noStore {
+		sent = entry.conditional(req)
+	}
+	reqTime := time.Now()
+	resp, err := next.Do(sent)
+	if err != nil {
+		return nil, err
+	}
+	respTime := time.Now()
+
+	if resp.StatusCode == http.StatusNotModified && sent != req {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		entry = entry.update(resp.Header, reqTime, respTime)
+		rc.save(key, entry)
+		return entry.response(req, respTime, "revalidated"), nil
+	}
+	if noStore || !storable(resp, opts.ttl) {
+		return resp, nil
+	}
+	if rc.alwaysRevalidate && opts.ttl == 0 && resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to cache: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	rc.save(key, &cacheEntry{
+		Status:       resp.Status,
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		Vary:         varyValues(resp.Header, req.Header),
+		RequestTime:  reqTime,
+		ResponseTime: respTime,
+		TTL:          opts.ttl,
+	})
+	return resp, nil
+}
+
+// lookup returns the entry stored for the request, if its Vary headers match
+func (rc *ResponseCache) lookup(key string, req *http.Request) *cacheEntry {
+	data, ok := rc.store.Get(key)
+	if !ok {
+		return nil
+	}
+	var entry *cacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry == nil {
+		return nil
+	}
+	for name, value := range entry.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != value {
+			return nil
+		}
+	}
+	return entry
+}
+
+// save stores entry, for as long as it is fresh unless validators allow
+// revalidating it later
+func (rc *ResponseCache) save(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	var ttl time.Duration
+	if entry.Header.Get("ETag") == "" && entry.Header.Get("Last-Modified") == "" {
+		ttl = entry.lifetime() - entry.age(time.Now())
+		if ttl <= 0 {
+			return
+		}
+	}
+	rc.store.Set(key, data, ttl)
+}
+
+// invalidate drops the responses to the URL of an unsafe request and the
+// URLs its response points to on the same host, as stored for the credentials
+// of the request and without credentials
+func (rc *ResponseCache) invalidate(req *http.Request, resp *http.Response) {
+	urls := []*url.URL{req.URL}
+	for _, h := range []string{"Location", "Content-Location"} {
+		if v := resp.Header.Get(h); v != "" {
+			if u, err := req.URL.Parse(v); err == nil && u.Host == req.URL.Host {
+				urls = append(urls, u)
+			}
+		}
+	}
+	for _, u := range urls {
+		rc.store.Delete(urlCacheKey(u, nil))
+		rc.store.Delete(urlCacheKey(u, req.Header))
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	return urlCacheKey(req.URL, req.Header)
+}
+
+// urlCacheKey identifies the response to a GET of u. Requests with an
+// Authorization header get entries of their own, keyed by a hash of it, since
+// servers often answer them per user without listing it in Vary.
+func urlCacheKey(u *url.URL, header http.Header) string {
+	key := http.MethodGet + " " + u.String()
+	if auth := header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		key += " " + hex.EncodeToString(sum[:])
+	}
+	return key
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// heuristicStatuses may be cached without explicit freshness information
+var heuristicStatuses = []int{200, 203, 204, 206, 300, 301, 404, 405, 410, 414, 501}
+
+// storable reports whether a response to a GET request may be stored; with a
+// ttl, it doesn't need freshness information
+func storable(resp *http.Response, ttl time.Duration) bool {
+	if !slices.Contains(heuristicStatuses, resp.StatusCode) && resp.StatusCode != http.StatusPermanentRedirect {
+		return false
+	}
+	if resp.StatusCode == http.StatusPartialContent || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if ttl > 0 {
+		return true
+	}
+	// without freshness information, validators still allow revalidation
+	_, maxAge := cc["max-age"]
+	_, noCache := cc["no-cache"]
+	return maxAge || noCache || resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// varyValues returns the request headers listed in the Vary header of resp
+func varyValues(respHeader, reqHeader http.Header) map[string]string {
+	var vary map[string]string
+	for _, v := range respHeader.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if vary == nil {
+				vary = make(map[string]string)
+			}
+			vary[name] = strings.Join(reqHeader.Values(name), ", ")
+		}
+	}
+	return vary
+}
+
+// parseCacheControl returns the Cache-Control directives with their values
+func parseCacheControl(h http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, v := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name != "" {
+				cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return cc
+}
+
+// seconds parses a delta-seconds directive value
+func seconds(v string) (time.Duration, bool) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// lifetime returns how long the response is fresh after it was generated
+func (e *cacheEntry) lifetime() time.Duration {
+	if e.TTL > 0 {
+		return e.TTL
+	}
+	cc := parseCacheControl(e.Header)
+	if d, ok := seconds(cc["max-age"]); ok {
+		return d
+	}
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.ResponseTime
+	}
+	if v := e.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+		return max(expires.Sub(date), 0)
+	}
+	// heuristic freshness: a tenth of the time since the last modification
+	if lm, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && slices.Contains(heuristicStatuses, e.StatusCode) {
+		return max(date.Sub(lm)/10, 0)
+	}
+	return 0
+}
+
+// age returns the current age of the response
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	apparent := time.Duration(0)
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		apparent = max(e.ResponseTime.Sub(date), 0)
+	}
+	ageValue, _ := seconds(e.Header.Get("Age"))
+	initial := max(apparent, ageValue+e.ResponseTime.Sub(e.RequestTime))
+	return initial + now.Sub(e.ResponseTime)
+}
+
+// fresh reports whether the entry may be served without revalidation under
+// the request's directives
+func (e *cacheEntry) fresh(reqCC map[string]string, now time.Time) bool {
+	respCC := parseCacheControl(e.Header)
+	if _, ok := respCC["no-cache"]; ok && e.TTL == 0 {
+		return false
+	}
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	lifetime, age := e.lifetime(), e.age(now)
+	if d, ok := seconds(reqCC["max-age"]); ok && age > d {
+		return false
+	}
+	if d, ok := seconds(reqCC["min-fresh"]); ok {
+		age += d
+	}
+	if age < lifetime {
+		return true
+	}
+	_, mustRevalidate := respCC["must-revalidate"]
+	if v, ok := reqCC["max-stale"]; ok && !mustRevalidate {
+		if v == "" {
+			return true
+		}
+		d, ok := seconds(v)
+		return ok && age-lifetime <= d
+	}
+	return false
+}
+
+// conditional returns req revalidating the entry with its validators
+func (e *cacheEntry) conditional(req *http.Request) *http.Request {
+	etag, lastModified := e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return req
+	}
+	cond := req.Clone(req.Context())
+	if etag != "" {
+		cond.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		cond.Header.Set("If-Modified-Since", lastModified)
+	}
+	return cond
+}
+
+// update returns the entry refreshed with the headers of a 304 response
+func (e *cacheEntry) update(header http.Header, reqTime, respTime time.Time) *cacheEntry {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	for k, v := range header {
+		if k != "Content-Length" {
+			updated.Header[k] = slices.Clone(v)
+		}
+	}
+	updated.RequestTime, updated.ResponseTime = reqTime, respTime
+	return &updated
+}
+
+// response builds a response to req from the entry
+func (e *cacheEntry) response(req *http.Request, now time.Time, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(e.age(now)/time.Second)))
+	header.Set(CacheStatusHeader, status)
+	return &http.Response{
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when a downloaded body doesn't match its expected digest
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// WithChecksum verifies a download against a hex encoded digest. Supported
+// algorithms are sha256, sha512, sha1 and md5.
+func WithChecksum(algorithm, digest string) RequestOption {
+	return func(o *requestOptions) {
+		o.checksumAlgorithm = algorithm
+		o.checksum = digest
+	}
+}
+
+// WithChecksumFromHeaders verifies a download against the digest advertised by
+// the server in Repr-Digest, Digest or Content-MD5, if any
+func WithChecksumFromHeaders() RequestOption {
+	return func(o *requestOptions) {
+		o.checksumFromHeaders = true
+	}
+}
+
+// checksumVerifier hashes a download as it is written and compares the result
+// with the expected digest
+type checksumVerifier struct {
+	algorithm string
+	expected  []byte
+	hash      hash.Hash
+}
+
+// newChecksumVerifier returns the verifier requested by o for resp, or nil
+// when there is nothing to verify
+func newChecksumVerifier(o *requestOptions, resp *Response) (*checksumVerifier, error) {
+	algorithm, expected := o.checksumAlgorithm, []byte(nil)
+	switch {
+	case o.checksum != "":
+		b, err := hex.DecodeString(o.checksum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s checksum %q: %w", algorithm, o.checksum, err)
+		}
+		expected = b
+	case o.checksumFromHeaders:
+		algorithm, expected = headerDigest(resp)
+		if expected == nil {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	h := newHash(algorithm)
+	if h == nil {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", algorithm)
+	}
+	return &checksumVerifier{algorithm: algorithm, expected: expected, hash: h}, nil
+}
+
+func (v *checksumVerifier) Write(p []byte) (int, error) {
+	return v.hash.Write(p)
+}
+
+// verify compares everything written so far with the expected digest
+func (v *checksumVerifier) verify() error {
+	if sum := v.hash.Sum(nil); !bytes.Equal(sum, v.expected) {
+		return fmt.Errorf("%w: %s expected %x, got %x", ErrChecksumMismatch, v.algorithm, v.expected, sum)
+	}
+	return nil
+}
+
+// hashFile feeds the first n bytes of the file at path into the hash
+func (v *checksumVerifier) hashFile(path string, n int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(v, f, n)
+	return err
+}
+
+func newHash(algorithm string) hash.Hash {
+	switch strings.ToLower(strings.ReplaceAll(algorithm, "-", "")) {
+	case "sha256":
+		return sha256.New()
+	case "sha512":
+		return sha512.New()
+	case "sha1", "sha":
+		return sha1.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// headerDigest returns the strongest digest of the full representation
+// advertised in resp: Repr-Digest (RFC 9530), Digest (RFC 3230), or
+// Content-MD5 for complete bodies
+func headerDigest(resp *Response) (string, []byte) {
+	var best string
+	var digest []byte
+	consider := func(algorithm, value string) {
+		b, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+		if err != nil || newHash(algorithm) == nil {
+			return
+		}
+		if digest == nil || digestRank(algorithm) > digestRank(best) {
+			best, digest = algorithm, b
+		}
+	}
+
+	for _, h := range []string{"Repr-Digest", "Digest"} {
+		for _, v := range resp.Headers[http.CanonicalHeaderKey(h)] {
+			for _, item := range strings.Split(v, ",") {
+				if algorithm, value, ok := strings.Cut(strings.TrimSpace(item), "="); ok {
+					consider(algorithm, value)
+				}
+			}
+		}
+	}
+	if digest == nil && resp.StatusCode == http.StatusOK {
+		if v := resp.Header("Content-MD5"); v != "" {
+			consider("md5", v)
+		}
+	}
+	return best, digest
+}
+
+func digestRank(algorithm string) int {
+	switch strings.ToLower(strings.ReplaceAll(algorithm, "-", "")) {
+	case "sha512":
+		return 3
+	case "sha256":
+		return 2
+	case "sha1", "sha":
+		return 1
+	}
+	return 0
+}
+
+// verifyFile checks the file at path, of size bytes, against the checksum requested by o
+func verifyFile(o *requestOptions, resp *Response, path string, size int64) error {
+	verifier, err := newChecksumVerifier(o, resp)
+	if err != nil || verifier == nil {
+		return err
+	}
+	if err := verifier.hashFile(path, size); err != nil {
+		return err
+	}
+	return verifier.verify()
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and waits between retries. Replace it with SetClock,
+// e.g. with muxettest.FakeClock to assert backoff delays without sleeping.
+type Clock interface {
+	Now() time.Time
+	// Sleep waits for d, or until ctx is done and returns its error
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the Clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error { return sleep(ctx, d) }
+
+// SetClock sets the clock the retry loop waits with and measures the elapsed
+// time and Retry-After dates by; nil restores the real clock
+func (c *Client) SetClock(clock Clock) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if clock == nil {
+		clock = realClock{}
+	}
+	c.clock = clock
+	return c
+}
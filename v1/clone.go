@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Clone returns a deep copy of c that can be reconfigured without affecting c,
+// e.g. to derive per-service clients from a shared base client. Headers,
+// parameters, default options, hooks, codecs, middleware and retry settings
+// are copied. Rate limits, concurrency limits, adaptive throttling, failover
+// and deduplication keep their settings but track their state separately. The
+// HTTPDoer, and with it the connection pool, is shared, as are a
+// CircuitBreaker and RetryBudget set on c; set new ones on the clone to
+// separate them.
+func (c *Client) Clone() *Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cp := *c
+	cp.mu = &sync.RWMutex{}
+	cp.middleware = slices.Clone(c.middleware)
+	cp.headers = maps.Clone(c.headers)
+	cp.queryParams = maps.Clone(c.queryParams)
+	cp.pathParams = maps.Clone(c.pathParams)
+	cp.encoders = maps.Clone(c.encoders)
+	cp.decoders = maps.Clone(c.decoders)
+	cp.errorModels = slices.Clone(c.errorModels)
+	cp.defaultOpts = slices.Clone(c.defaultOpts)
+	cp.beforeHooks = slices.Clone(c.beforeHooks)
+	cp.afterHooks = slices.Clone(c.afterHooks)
+
+	cp.throttle = c.throttle.clone()
+	cp.concurrency = c.concurrency.clone()
+	if c.rateLimit != nil {
+		cp.rateLimit = c.rateLimit.clone()
+	}
+	if c.failover != nil {
+		cp.failover = c.failover.clone()
+	}
+	if c.dedup != nil {
+		cp.dedup = &flightGroup{}
+	}
+	return &cp
+}
+
+func (t *throttle) clone() *throttle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &throttle{enabled: t.enabled}
+}
+
+func (l *concurrencyLimit) clone() *concurrencyLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total semaphore
+	if l.total != nil {
+		total = make(semaphore, cap(l.total))
+	}
+	return &concurrencyLimit{total: total, perHost: l.perHost}
+}
+
+func (r *rateLimit) clone() *rateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &rateLimit{rps: r.rps, burst: r.burst, perHost: r.perHost, nonBlocking: r.nonBlocking}
+}
+
+func (f *failover) clone() *failover {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return newFailover(f.bases, f.coolDown)
+}
@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Encoder marshals a request body for a media type, like json.Marshal
+type Encoder func(v any) ([]byte, error)
+
+// Decoder unmarshals a response body of a media type, like json.Unmarshal
+type Decoder func(data []byte, v any) error
+
+// SetEncoder registers enc for request bodies sent with Content-Type mediaType.
+// Bodies without a registered encoder are sent as JSON.
+func (c *Client) SetEncoder(mediaType string, enc Encoder) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	encoders := maps.Clone(c.encoders)
+	if encoders == nil {
+		encoders = make(map[string]Encoder)
+	}
+	encoders[parseMediaType(mediaType)] = enc
+	c.encoders = encoders
+	return c
+}
+
+// SetDecoder registers dec for responses with Content-Type mediaType.
+// Responses without a registered decoder are decoded as JSON.
+func (c *Client) SetDecoder(mediaType string, dec Decoder) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	decoders := maps.Clone(c.decoders)
+	if decoders == nil {
+		decoders = make(map[string]Decoder)
+	}
+	decoders[parseMediaType(mediaType)] = dec
+	c.decoders = decoders
+	return c
+}
+
+// parseMediaType returns the lowercased media type of a Content-Type value
+// without its parameters
+func parseMediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mt, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// lookupCodec finds the codec for contentType, falling back from structured
+// syntax suffixes like application/vnd.api+json to application/json
+func lookupCodec[T any](codecs map[string]T, contentType string) (T, bool) {
+	var zero T
+	if len(codecs) == 0 || contentType == "" {
+		return zero, false
+	}
+	mt := parseMediaType(contentType)
+	if codec, ok := codecs[mt]; ok {
+		return codec, true
+	}
+	if _, suffix, ok := strings.Cut(mt, "+"); ok {
+		if codec, ok := codecs["application/"+suffix]; ok {
+			return codec, true
+		}
+	}
+	return zero, false
+}
+
+// Decode stores the body of resp in out like the out argument of DoRequest,
+// with the decoders registered on c
+func (c *Client) Decode(resp *Response, out any) error {
+	return c.decode(resp, out)
+}
+
+// decode stores the response body in out: raw for *string, with the decoder
+// registered for its Content-Type, or as JSON otherwise. A streamed body is
+// closed afterwards, and decoded incrementally when it is JSON. 304 Not
+// Modified and unfollowed redirects leave out alone.
+func (c *Client) decode(muxResp *Response, out any) error {
+	if out == nil {
+		return nil
+	}
+	if muxResp.StatusCode == http.StatusNotModified || muxResp.unfollowed {
+		if muxResp.Stream != nil {
+			muxResp.Stream.Close()
+		}
+		return nil
+	}
+	c.mu.RLock()
+	dec, ok := lookupCodec(c.decoders, muxResp.Header("Content-Type"))
+	c.mu.RUnlock()
+	s, raw := out.(*string)
+
+	data := muxResp.Body
+	if muxResp.Stream != nil {
+		defer muxResp.Stream.Close()
+		if !ok && !raw {
+			if err := json.NewDecoder(muxResp.Stream).Decode(out); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		}
+		var err error
+		if data, err = io.ReadAll(muxResp.Stream); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+	}
+	switch {
+	case raw:
+		*s = string(data)
+		return nil
+	case !ok:
+		dec = json.Unmarshal
+	}
+	if err := dec(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// headerValue returns the value of key in headers in any casing
+func headerValue(headers map[string]string, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
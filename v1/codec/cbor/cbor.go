@@ -0,0 +1,17 @@
+// Package cbor registers a CBOR (RFC 8949) codec with muxet clients.
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// MediaType is the Content-Type of CBOR bodies
+const MediaType = "application/cbor"
+
+// Register makes c encode bodies sent with Content-Type application/cbor
+// and decode CBOR responses
+func Register(c *muxet.Client) *muxet.Client {
+	return c.SetEncoder(MediaType, cbor.Marshal).SetDecoder(MediaType, cbor.Unmarshal)
+}
@@ -0,0 +1,23 @@
+// Package msgpack registers a MessagePack codec with muxet clients.
+package msgpack
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// MediaType is the Content-Type of MessagePack bodies
+const MediaType = "application/msgpack"
+
+// legacy media types still used by many servers
+var aliases = []string{"application/x-msgpack", "application/vnd.msgpack"}
+
+// Register makes c encode bodies sent with Content-Type application/msgpack
+// and decode MessagePack responses
+func Register(c *muxet.Client) *muxet.Client {
+	for _, mt := range append([]string{MediaType}, aliases...) {
+		c.SetEncoder(mt, msgpack.Marshal).SetDecoder(mt, msgpack.Unmarshal)
+	}
+	return c
+}
@@ -0,0 +1,55 @@
+// Package protobuf registers a Protocol Buffers codec with muxet clients,
+// for gRPC-gateway and Twirp style services.
+package protobuf
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// MediaType is the Content-Type of protobuf bodies
+const MediaType = "application/x-protobuf"
+
+// other media types in use for binary protobuf
+var aliases = []string{"application/protobuf", "application/vnd.google.protobuf"}
+
+// Register makes c encode proto.Message bodies sent with Content-Type
+// application/x-protobuf and decode protobuf responses into proto messages
+func Register(c *muxet.Client) *muxet.Client {
+	for _, mt := range append([]string{MediaType}, aliases...) {
+		c.SetEncoder(mt, Marshal).SetDecoder(mt, Unmarshal)
+	}
+	return c
+}
+
+// Marshal encodes v, which must be a proto.Message
+func Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes data into v, a proto.Message or a pointer to one. A nil
+// message behind the pointer is allocated, as the typed helpers pass **T.
+func Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		elem := rv.Elem()
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		if m, ok := elem.Interface().(proto.Message); ok {
+			return proto.Unmarshal(data, m)
+		}
+	}
+	return fmt.Errorf("protobuf: %T is not a proto.Message", v)
+}
@@ -0,0 +1,57 @@
+// Package yaml registers a YAML codec with muxet clients. Values are converted
+// through JSON, so structs keep using their json tags as Kubernetes does.
+// Importing it lets muxet.LoadProfiles read .yaml and .yml files.
+package yaml
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// MediaType is the Content-Type of YAML bodies (RFC 9512)
+const MediaType = "application/yaml"
+
+// media types used for YAML before it was registered
+var aliases = []string{"application/x-yaml", "text/yaml", "text/x-yaml"}
+
+func init() {
+	muxet.RegisterProfileFormat(".yaml", Unmarshal)
+	muxet.RegisterProfileFormat(".yml", Unmarshal)
+}
+
+// Register makes c encode bodies sent with Content-Type application/yaml
+// and decode YAML responses
+func Register(c *muxet.Client) *muxet.Client {
+	for _, mt := range append([]string{MediaType}, aliases...) {
+		c.SetEncoder(mt, Marshal).SetDecoder(mt, Unmarshal)
+	}
+	return c
+}
+
+// Marshal encodes v as YAML
+func Marshal(v any) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+// Unmarshal decodes YAML data into v
+func Unmarshal(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// LoadProfiles reads client profiles from a YAML file whatever its extension,
+// see muxet.LoadProfiles
+func LoadProfiles(path string) (muxet.Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	profiles, err := muxet.ParseProfiles(data, Unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles from %s: %w", path, err)
+	}
+	return profiles, nil
+}
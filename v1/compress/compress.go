@@ -0,0 +1,159 @@
+// Package compress adds brotli and zstd response decoding to muxet clients.
+// Go's transport only decodes gzip, and only when it negotiated it itself.
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// ErrTooLarge is returned while reading a body that decompresses to more than the limit
+var ErrTooLarge = errors.New("decompressed body exceeds size limit")
+
+// AcceptEncoding is the Accept-Encoding header sent by the middleware
+const AcceptEncoding = "br, zstd, gzip, deflate"
+
+// Middleware advertises br, zstd, gzip and deflate and decodes responses, so
+// the body is always the plain payload. Bodies decompressing to more than
+// maxSize bytes fail with ErrTooLarge, guarding against zip bombs; 0 disables
+// the limit.
+func Middleware(maxSize int64) muxet.Middleware {
+	return func(next muxet.HTTPDoer) muxet.HTTPDoer {
+		return muxet.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Accept-Encoding") == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set("Accept-Encoding", AcceptEncoding)
+			}
+			resp, err := next.Do(req)
+			if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+				return resp, err
+			}
+
+			codings := parseCodings(resp.Header.Get("Content-Encoding"))
+			if len(codings) == 0 || !supported(codings) {
+				return resp, nil
+			}
+			resp.Body = &decodingBody{src: resp.Body, codings: codings, limit: maxSize}
+			resp.Header.Del("Content-Encoding")
+			resp.Header.Del("Content-Length")
+			resp.ContentLength = -1
+			resp.Uncompressed = true
+			return resp, nil
+		})
+	}
+}
+
+// parseCodings splits Content-Encoding into codings in the order they were applied
+func parseCodings(v string) []string {
+	var codings []string
+	for c := range strings.SplitSeq(v, ",") {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" && c != "identity" {
+			codings = append(codings, c)
+		}
+	}
+	return codings
+}
+
+func supported(codings []string) bool {
+	for _, c := range codings {
+		switch c {
+		case "br", "zstd", "gzip", "x-gzip", "deflate":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// decodingBody decodes the response body on first read, so that empty bodies
+// and bodies that are never read don't fail
+type decodingBody struct {
+	src     io.ReadCloser
+	codings []string
+	limit   int64
+	r       io.Reader
+	closers []func()
+	err     error
+}
+
+func (b *decodingBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.err = b.init()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+// init stacks a decoder for each coding, undoing the last applied one first
+func (b *decodingBody) init() error {
+	var r io.Reader = b.src
+	for i := len(b.codings) - 1; i >= 0; i-- {
+		switch b.codings[i] {
+		case "br":
+			r = brotli.NewReader(r)
+		case "zstd":
+			zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return err
+			}
+			b.closers = append(b.closers, zr.Close)
+			r = zr
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return err
+			}
+			r = zr
+		case "deflate":
+			zr, err := zlib.NewReader(r)
+			if err != nil {
+				return err
+			}
+			r = zr
+		}
+	}
+	if b.limit > 0 {
+		r = &limitReader{r: r, remaining: b.limit}
+	}
+	b.r = r
+	return nil
+}
+
+func (b *decodingBody) Close() error {
+	for _, fn := range b.closers {
+		fn()
+	}
+	return b.src.Close()
+}
+
+// limitReader fails with ErrTooLarge once more than remaining bytes are read
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrTooLarge
+	}
+	return n, err
+}
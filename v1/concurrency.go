@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"context"
+	"sync"
+)
+
+// semaphore bounds the number of holders; a nil semaphore is unbounded
+type semaphore chan struct{}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// concurrencyLimit caps in-flight requests for the whole client and per host
+type concurrencyLimit struct {
+	mu      sync.Mutex
+	total   semaphore
+	perHost int
+	hosts   map[string]semaphore
+}
+
+func (l *concurrencyLimit) host(host string) semaphore {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perHost <= 0 {
+		return nil
+	}
+	if l.hosts == nil {
+		l.hosts = make(map[string]semaphore)
+	}
+	s, ok := l.hosts[host]
+	if !ok {
+		s = make(semaphore, l.perHost)
+		l.hosts[host] = s
+	}
+	return s
+}
+
+// acquire waits for a free slot for host; the returned func frees it again
+func (l *concurrencyLimit) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	total := l.total
+	l.mu.Unlock()
+
+	if err := total.acquire(ctx); err != nil {
+		return nil, err
+	}
+	perHost := l.host(host)
+	if err := perHost.acquire(ctx); err != nil {
+		total.release()
+		return nil, err
+	}
+	return func() {
+		perHost.release()
+		total.release()
+	}, nil
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Config is the part of the client configuration that can be swapped at
+// runtime with Client.UpdateConfig, e.g. by a watcher rotating endpoints and
+// tokens. Credentials are sent as headers, like "Authorization".
+type Config struct {
+	BaseURL        string
+	Headers        map[string]string
+	QueryParams    url.Values
+	Timeout        time.Duration
+	AttemptTimeout time.Duration
+	MaxRetries     int
+	Backoff        Backoff
+	MaxElapsedTime time.Duration
+}
+
+// Config returns a copy of the current configuration
+func (c *Client) Config() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config()
+}
+
+// UpdateConfig changes the configuration atomically: fn edits a copy of the
+// current configuration, which replaces it as a whole once fn returns.
+// Requests in flight finish with the configuration they started with.
+//
+//	client.UpdateConfig(func(cfg *muxet.Config) {
+//		cfg.BaseURL = endpoint
+//		cfg.Headers["Authorization"] = "Bearer " + token
+//	})
+func (c *Client) UpdateConfig(fn func(*Config)) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg := c.config()
+	fn(&cfg)
+
+	c.setBaseURL(cfg.BaseURL)
+	c.headers = maps.Clone(cfg.Headers)
+	if c.headers == nil {
+		c.headers = make(map[string]string)
+	}
+	c.queryParams = cloneValues(cfg.QueryParams)
+	c.timeout = cfg.Timeout
+	c.attemptTimeout = cfg.AttemptTimeout
+	c.maxRetries = cfg.MaxRetries
+	c.backoff = cfg.Backoff
+	c.maxElapsed = cfg.MaxElapsedTime
+	return c
+}
+
+// config copies the configuration; c.mu must be held
+func (c *Client) config() Config {
+	return Config{
+		BaseURL:        c.BaseURL,
+		Headers:        maps.Clone(c.headers),
+		QueryParams:    cloneValues(c.queryParams),
+		Timeout:        c.timeout,
+		AttemptTimeout: c.attemptTimeout,
+		MaxRetries:     c.maxRetries,
+		Backoff:        c.backoff,
+		MaxElapsedTime: c.maxElapsed,
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	cp := make(url.Values, len(v))
+	for k, vs := range v {
+		cp[k] = slices.Clone(vs)
+	}
+	return cp
+}
@@ -0,0 +1,230 @@
+package v1
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileCookieJar is a cookie jar kept in a file, so CLI tools can keep their
+// sessions between runs. The file is rewritten whenever a response changes
+// the cookies; expired cookies are dropped, and session cookies, without
+// expiry, are kept like a browser restoring its session. With a key, the file
+// is encrypted with AES-GCM.
+type FileCookieJar struct {
+	path string
+	aead cipher.AEAD
+
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]storedCookie
+}
+
+// storedCookie is a cookie in the file, with the URL that set it
+type storedCookie struct {
+	URL      string        `json:"url"`
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Expires  time.Time     `json:"expires,omitzero"`
+	Secure   bool          `json:"secure,omitempty"`
+	HTTPOnly bool          `json:"httpOnly,omitempty"`
+	SameSite http.SameSite `json:"sameSite,omitempty"`
+}
+
+// NewFileCookieJar returns a jar stored in path, loading the cookies it
+// already holds. A key of 16, 24 or 32 bytes encrypts the file; nil leaves it
+// in plain JSON. Set it with client.SetCookieJar(jar).
+func NewFileCookieJar(path string, key []byte) (*FileCookieJar, error) {
+	j := &FileCookieJar{path: path}
+	if key != nil {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie jar key: %w", err)
+		}
+		j.aead, _ = cipher.NewGCM(block)
+	}
+	j.reset()
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// SetCookies stores the cookies received from u and saves the file
+func (j *FileCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+	now := time.Now()
+	for _, c := range cookies {
+		sc := storedCookie{
+			URL:      (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+			SameSite: c.SameSite,
+		}
+		// Max-Age becomes an absolute expiry so it holds in the next run
+		if c.MaxAge > 0 {
+			sc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		key := cookieKey(u, c)
+		if c.MaxAge < 0 || !sc.Expires.IsZero() && !sc.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = sc
+	}
+	// the jar can't report errors, Save does
+	j.save()
+}
+
+// Cookies returns the cookies to send to u
+func (j *FileCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// Save writes the cookies to the file
+func (j *FileCookieJar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.save()
+}
+
+// Clear drops every cookie, e.g. on logout, and saves the empty jar
+func (j *FileCookieJar) Clear() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.reset()
+	return j.save()
+}
+
+func (j *FileCookieJar) reset() {
+	j.jar, _ = cookiejar.New(nil)
+	j.cookies = make(map[string]storedCookie)
+}
+
+func (j *FileCookieJar) load() error {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	if j.aead != nil {
+		n := j.aead.NonceSize()
+		if len(data) < n {
+			return errors.New("failed to decrypt cookie jar: file too short")
+		}
+		if data, err = j.aead.Open(nil, data[:n], data[n:], nil); err != nil {
+			return fmt.Errorf("failed to decrypt cookie jar: %w", err)
+		}
+	}
+	var stored []storedCookie
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to decode cookie jar: %w", err)
+	}
+
+	now := time.Now()
+	for _, sc := range stored {
+		u, err := url.Parse(sc.URL)
+		if err != nil || !sc.Expires.IsZero() && !sc.Expires.After(now) {
+			continue
+		}
+		c := &http.Cookie{
+			Name:     sc.Name,
+			Value:    sc.Value,
+			Domain:   sc.Domain,
+			Path:     sc.Path,
+			Expires:  sc.Expires,
+			Secure:   sc.Secure,
+			HttpOnly: sc.HTTPOnly,
+			SameSite: sc.SameSite,
+		}
+		j.jar.SetCookies(u, []*http.Cookie{c})
+		j.cookies[cookieKey(u, c)] = sc
+	}
+	return nil
+}
+
+// save writes the unexpired cookies through a temporary file, so a crash
+// never leaves a truncated jar
+func (j *FileCookieJar) save() error {
+	now := time.Now()
+	stored := make([]storedCookie, 0, len(j.cookies))
+	for key, sc := range j.cookies {
+		if !sc.Expires.IsZero() && !sc.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		stored = append(stored, sc)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode cookie jar: %w", err)
+	}
+	if j.aead != nil {
+		nonce := make([]byte, j.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("failed to encrypt cookie jar: %w", err)
+		}
+		data = j.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), j.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// cookieKey identifies a cookie the way the jar does, by domain, path and name
+func cookieKey(u *url.URL, c *http.Cookie) string {
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if domain == "" {
+		domain = strings.ToLower(u.Hostname())
+	}
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		// the default path is the directory of the request path
+		path = u.Path
+		if i := strings.LastIndex(path, "/"); i > 0 {
+			path = path[:i]
+		} else {
+			path = "/"
+		}
+	}
+	return domain + ";" + path + ";" + c.Name
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// AsCurl returns a curl command that sends the request like muxet does, with
+// its headers and encoded body. Streamed bodies, like readers and multipart
+// uploads, can't be shown and are left out.
+func (r *Request) AsCurl() string {
+	return r.curl(nil)
+}
+
+// SetCurlOnError logs a curl command reproducing each request that fails for
+// good, with credentials redacted
+func (c *Client) SetCurlOnError(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.curlOnError = enabled
+	return c
+}
+
+// curl builds the curl command; with a client, it is redacted and its body
+// cut for logging
+func (r *Request) curl(c *Client) string {
+	var body *requestBody
+	if r.Body != nil {
+		enc, _ := lookupCodec(r.encoders, headerValue(r.Headers, "Content-Type"))
+		body, _ = newRequestBody(r.Body, enc)
+	}
+
+	headers := make(map[string]string, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	if body != nil && body.contentType != "" && (body.fixedType || headers["Content-Type"] == "") {
+		headers["Content-Type"] = body.contentType
+	}
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString("curl")
+	switch r.Method {
+	case http.MethodGet, "":
+	case http.MethodHead:
+		b.WriteString(" --head")
+	default:
+		b.WriteString(" -X " + r.Method)
+	}
+	b.WriteString(" " + shellQuote(r.URL))
+	for _, k := range keys {
+		v := headers[k]
+		if c != nil {
+			v = c.redactHeader(k, v)
+		}
+		b.WriteString(" -H " + shellQuote(k+": "+v))
+	}
+	switch {
+	case body == nil:
+	case body.data != nil && c == nil:
+		b.WriteString(" --data-binary " + shellQuote(string(body.data)))
+	case body.data != nil:
+		data := c.redactBody(body.data)
+		if len(data) > c.maxLoggedBody {
+			b.WriteString(" --data-binary " + shellQuote(string(data[:max(c.maxLoggedBody, 0)])))
+			fmt.Fprintf(&b, " # body truncated, %d bytes total", len(data))
+			break
+		}
+		b.WriteString(" --data-binary " + shellQuote(string(data)))
+	default:
+		b.WriteString(" # streamed body left out")
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
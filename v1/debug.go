@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SetDebug logs the request and response of every attempt, with headers and
+// the start of their bodies, through the logger or the standard logger if
+// none is set. Credentials and cookies are redacted, see AddRedactedHeaders
+// for more redaction rules.
+func (c *Client) SetDebug(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.debug = enabled
+	return c
+}
+
+// SetDebugBodyLimit sets how many bytes of each body debug logs show; 0 hides bodies
+//
+// Deprecated: use SetMaxLoggedBodySize, which also covers logged curl commands.
+func (c *Client) SetDebugBodyLimit(n int) *Client {
+	return c.SetMaxLoggedBodySize(n)
+}
+
+// debugLog logs a debug dump
+func (c *Client) debugLog(ctx context.Context, msg, dump string) {
+	dump = strings.TrimSuffix(dump, "\n")
+	c.printf(ctx, slog.LevelDebug, msg, []slog.Attr{slog.String("dump", dump)}, "%s", dump)
+}
+
+// dumpRequest logs an attempt about to be sent. Only bodies held in memory
+// are shown, streams are left alone.
+func (c *Client) dumpRequest(req *http.Request, body *requestBody) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s %s\n", req.Method, req.URL, req.Proto)
+	if req.Host != "" && req.Host != req.URL.Host {
+		fmt.Fprintf(&b, "Host: %s\n", req.Host)
+	}
+	c.writeDebugHeaders(&b, req.Header)
+	switch {
+	case body == nil:
+	case body.data != nil:
+		c.writeDebugBody(&b, body.data)
+	default:
+		b.WriteString("\n[streamed body]\n")
+	}
+	c.debugLog(req.Context(), "Request dump", b.String())
+}
+
+// dumpResponse logs the outcome of an attempt
+func (c *Client) dumpResponse(req *http.Request, resp *http.Response, muxResp *Response, err error, elapsed time.Duration) {
+	var b strings.Builder
+	if resp == nil {
+		fmt.Fprintf(&b, "<-- %s %s failed after %s: %v\n", req.Method, req.URL, elapsed.Round(time.Millisecond), err)
+		c.debugLog(req.Context(), "Response dump", b.String())
+		return
+	}
+	fmt.Fprintf(&b, "<-- %s %s %s (%s)\n", resp.Proto, resp.Status, req.URL, elapsed.Round(time.Millisecond))
+	c.writeDebugHeaders(&b, resp.Header)
+	switch {
+	case muxResp == nil:
+	case muxResp.Stream != nil:
+		b.WriteString("\n[streamed body]\n")
+	case len(muxResp.Body) > 0:
+		c.writeDebugBody(&b, muxResp.Body)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "error: %v\n", err)
+	}
+	c.debugLog(req.Context(), "Response dump", b.String())
+}
+
+func (c *Client) writeDebugHeaders(b *strings.Builder, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s: %s\n", k, c.redactHeader(k, v))
+		}
+	}
+}
+
+func (c *Client) writeDebugBody(b *strings.Builder, data []byte) {
+	if c.maxLoggedBody <= 0 {
+		return
+	}
+	b.WriteByte('\n')
+	data = c.redactBody(data)
+	shown := data[:min(len(data), c.maxLoggedBody)]
+	// cut at a character boundary
+	for len(shown) > 0 && len(shown) < len(data) && !utf8.RuneStart(data[len(shown)]) {
+		shown = shown[:len(shown)-1]
+	}
+	if !utf8.Valid(shown) {
+		fmt.Fprintf(b, "[binary body, %d bytes]\n", len(data))
+		return
+	}
+	b.Write(shown)
+	if len(shown) < len(data) {
+		fmt.Fprintf(b, "\n[truncated, %d bytes total]", len(data))
+	}
+	b.WriteByte('\n')
+}
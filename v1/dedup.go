@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// flightGroup coalesces concurrent identical requests into a single upstream call
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	resp    *http.Response
+	muxResp *Response
+	err     error
+}
+
+// do runs fn once per key at a time; callers arriving while it is in flight
+// wait for and share its result
+func (g *flightGroup) do(key string, fn func() (*http.Response, *Response, error)) (*http.Response, *Response, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-f.done
+		return f.resp, f.muxResp, f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.resp, f.muxResp, f.err = fn()
+	return f.resp, f.muxResp, f.err
+}
+
+// flightKey identifies a request by method, URL and headers other than its request ID
+func flightKey(r *Request, idHeader string) string {
+	keys := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		if idHeader == "" || !strings.EqualFold(k, idHeader) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.URL)
+	for _, k := range keys {
+		b.WriteByte('\n')
+		b.WriteString(http.CanonicalHeaderKey(k))
+		b.WriteByte(':')
+		b.WriteString(r.Headers[k])
+	}
+	return b.String()
+}
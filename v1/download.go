@@ -0,0 +1,404 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress describes the state of a transfer
+type Progress struct {
+	// Transferred is the number of bytes transferred so far
+	Transferred int64
+	// Total is the expected size from Content-Length, or -1 if unknown
+	Total int64
+	// Rate is the average transfer rate in bytes per second
+	Rate float64
+	// Elapsed is the time since the transfer started
+	Elapsed time.Duration
+}
+
+// progressInterval throttles progress callbacks
+const progressInterval = 100 * time.Millisecond
+
+// WithDownloadProgress reports download progress to fn, at most every 100ms and once more when done
+func WithDownloadProgress(fn func(Progress)) RequestOption {
+	return func(o *requestOptions) {
+		o.downloadProgress = fn
+	}
+}
+
+// Download streams the body of url into dst without buffering it in memory
+func (c *Client) Download(ctx context.Context, url string, dst io.Writer, opts ...RequestOption) (*Response, error) {
+	_, resp, err := c.do(ctx, http.MethodGet, url, nil, nil, append(opts[:len(opts):len(opts)], WithStreamBody()))
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Stream.Close()
+
+	o := newRequestOptions(opts)
+	verifier, err := newChecksumVerifier(o, resp)
+	if err != nil {
+		return resp, err
+	}
+
+	pw := newProgressWriter(dst, resp.Raw.ContentLength, o.downloadProgress)
+	var w io.Writer = pw
+	if verifier != nil {
+		w = io.MultiWriter(pw, verifier)
+	}
+	if _, err := io.Copy(w, resp.Stream); err != nil {
+		return resp, fmt.Errorf("failed to download body: %w", err)
+	}
+	if verifier != nil {
+		if err := verifier.verify(); err != nil {
+			return resp, err
+		}
+	}
+	pw.report(true)
+	return resp, nil
+}
+
+// WithResume makes DownloadFile continue a partial download of an earlier
+// attempt instead of starting over
+func WithResume() RequestOption {
+	return func(o *requestOptions) {
+		o.resume = true
+	}
+}
+
+// DownloadFile downloads url into the file at path. The file is removed if the
+// download fails, unless WithResume is given: then the partial file is kept
+// and the next call resumes it with a Range request.
+func (c *Client) DownloadFile(ctx context.Context, url, path string, opts ...RequestOption) (*Response, error) {
+	if newRequestOptions(opts).resume {
+		return c.resumeDownload(ctx, url, path, opts)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Download(ctx, url, f, opts...)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return resp, err
+}
+
+// resumeDownload downloads url into path, continuing from the bytes already on
+// disk. The validator (strong ETag or Last-Modified) of the response is kept in
+// a sidecar file and sent as If-Range, so a changed resource is fetched anew.
+func (c *Client) resumeDownload(ctx context.Context, url, path string, opts []RequestOption) (*Response, error) {
+	meta := path + ".resume"
+
+	var offset int64
+	var validator string
+	if fi, err := os.Stat(path); err == nil && fi.Size() > 0 {
+		if b, err := os.ReadFile(meta); err == nil && len(b) > 0 {
+			offset, validator = fi.Size(), string(b)
+		}
+	}
+
+	reqOpts := append(opts[:len(opts):len(opts)], WithStreamBody())
+	if offset > 0 {
+		reqOpts = append(reqOpts,
+			WithHeader("Range", fmt.Sprintf("bytes=%d-", offset)),
+			WithHeader("If-Range", validator))
+	}
+
+	_, resp, err := c.do(ctx, http.MethodGet, url, nil, nil, reqOpts)
+	if err != nil {
+		var httpErr *HTTPError
+		if offset > 0 && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// nothing left to fetch if the file on disk is already complete
+			if _, _, total, ok := parseContentRange(httpErr.Headers.Get("Content-Range")); ok && total == offset {
+				os.Remove(meta)
+				return resp, nil
+			}
+		}
+		return resp, err
+	}
+	defer resp.Stream.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if resp.StatusCode == http.StatusPartialContent {
+		start, _, _, ok := parseContentRange(resp.Header("Content-Range"))
+		if !ok || start != offset {
+			return resp, fmt.Errorf("unexpected Content-Range %q for offset %d", resp.Header("Content-Range"), offset)
+		}
+		flags |= os.O_APPEND
+	} else {
+		offset = 0
+		flags |= os.O_TRUNC
+	}
+
+	if v := resumeValidator(resp); v != "" {
+		if err := os.WriteFile(meta, []byte(v), 0o644); err != nil {
+			return resp, err
+		}
+	} else {
+		os.Remove(meta)
+	}
+
+	o := newRequestOptions(opts)
+	verifier, err := newChecksumVerifier(o, resp)
+	if err != nil {
+		return resp, err
+	}
+	if verifier != nil && offset > 0 {
+		if err := verifier.hashFile(path, offset); err != nil {
+			return resp, err
+		}
+	}
+
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return resp, err
+	}
+	pw := newProgressWriter(f, resp.Raw.ContentLength, o.downloadProgress)
+	pw.offset = offset
+	var w io.Writer = pw
+	if verifier != nil {
+		w = io.MultiWriter(pw, verifier)
+	}
+	_, err = io.Copy(w, resp.Stream)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return resp, fmt.Errorf("failed to download body: %w", err)
+	}
+	os.Remove(meta)
+	if verifier != nil {
+		if err := verifier.verify(); err != nil {
+			// a corrupt file can't be resumed
+			os.Remove(path)
+			return resp, err
+		}
+	}
+	pw.report(true)
+	return resp, nil
+}
+
+// resumeValidator returns the value to send as If-Range: a strong ETag, or
+// else Last-Modified
+func resumeValidator(resp *Response) string {
+	if etag := resp.Header("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header("Last-Modified")
+}
+
+// parseContentRange parses "bytes start-end/total" and "bytes */total".
+// Unknown values are returned as -1.
+func parseContentRange(v string) (start, end, total int64, ok bool) {
+	unit, rest, found := strings.Cut(strings.TrimSpace(v), " ")
+	if !found || unit != "bytes" {
+		return 0, 0, 0, false
+	}
+	rng, size, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		total = n
+	}
+	if rng == "*" {
+		return -1, -1, total, true
+	}
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, 0, false
+	}
+	return start, end, total, true
+}
+
+// progressWriter counts bytes written to w and reports them to fn
+type progressWriter struct {
+	w       io.Writer
+	fn      func(Progress)
+	start   time.Time
+	last    time.Time
+	total   int64
+	written int64
+	// offset is the number of bytes transferred before, e.g. when resuming
+	offset int64
+}
+
+func newProgressWriter(w io.Writer, total int64, fn func(Progress)) *progressWriter {
+	return &progressWriter{w: w, fn: fn, total: total, start: time.Now()}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.report(false)
+	return n, err
+}
+
+func (p *progressWriter) report(final bool) {
+	if p.fn == nil {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(p.last) < progressInterval {
+		return
+	}
+	p.last = now
+	elapsed := now.Sub(p.start)
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.written) / elapsed.Seconds()
+	}
+	total := p.total
+	if total >= 0 {
+		total += p.offset
+	}
+	p.fn(Progress{
+		Transferred: p.offset + p.written,
+		Total:       total,
+		Rate:        rate,
+		Elapsed:     elapsed,
+	})
+}
+
+// minPartSize keeps DownloadParallel from splitting small files
+const minPartSize = 1 << 20
+
+// DownloadParallel downloads url into path as parts byte ranges fetched
+// concurrently. It falls back to a single stream when the server doesn't
+// advertise range support or the file is too small to be worth splitting.
+func (c *Client) DownloadParallel(ctx context.Context, url, path string, parts int, opts ...RequestOption) (*Response, error) {
+	_, head, err := c.do(ctx, http.MethodHead, url, nil, nil, opts)
+	if err != nil {
+		return head, err
+	}
+	size := head.Raw.ContentLength
+	if parts <= 1 || size < 2*minPartSize || head.Header("Accept-Ranges") != "bytes" {
+		return c.DownloadFile(ctx, url, path, opts...)
+	}
+	parts = int(min(int64(parts), size/minPartSize))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return head, err
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		os.Remove(path)
+		return head, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// keep all parts on the same version of the resource
+	partOpts := append(opts[:len(opts):len(opts)], WithStreamBody())
+	if etag := head.Header("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		partOpts = append(partOpts, WithHeader("If-Range", etag))
+	}
+
+	o := newRequestOptions(opts)
+	progress := &syncProgress{pw: newProgressWriter(io.Discard, size, o.downloadProgress)}
+	errs := make(chan error, parts)
+	chunk := size / int64(parts)
+	for i := range parts {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == parts-1 {
+			end = size - 1
+		}
+		go func() {
+			err := c.downloadRange(ctx, url, f, start, end, progress, partOpts)
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}()
+	}
+
+	var firstErr error
+	for range parts {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := f.Close(); firstErr == nil {
+		firstErr = err
+	}
+	if firstErr == nil {
+		firstErr = verifyFile(o, head, path, size)
+	}
+	if firstErr != nil {
+		os.Remove(path)
+		return head, firstErr
+	}
+	progress.report()
+	return head, nil
+}
+
+// downloadRange fetches bytes [start, end] of url into f at the same offset
+func (c *Client) downloadRange(ctx context.Context, url string, f *os.File, start, end int64, progress *syncProgress, opts []RequestOption) error {
+	opts = append(opts[:len(opts):len(opts)], WithHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)))
+	_, resp, err := c.do(ctx, http.MethodGet, url, nil, nil, opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Stream.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("range %d-%d: expected 206 Partial Content, got %d", start, end, resp.StatusCode)
+	}
+	if first, last, _, ok := parseContentRange(resp.Header("Content-Range")); !ok || first != start || last != end {
+		return fmt.Errorf("range %d-%d: unexpected Content-Range %q", start, end, resp.Header("Content-Range"))
+	}
+
+	w := io.NewOffsetWriter(f, start)
+	n, err := io.Copy(io.MultiWriter(w, progress), io.LimitReader(resp.Stream, end-start+1))
+	if err != nil {
+		return fmt.Errorf("range %d-%d: %w", start, end, err)
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("range %d-%d: short body of %d bytes", start, end, n)
+	}
+	return nil
+}
+
+// syncProgress aggregates progress of concurrent transfers
+type syncProgress struct {
+	mu sync.Mutex
+	pw *progressWriter
+}
+
+func (p *syncProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pw.Write(b)
+}
+
+func (p *syncProgress) report() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pw.report(true)
+}
@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"syscall"
+)
+
+// HTTPError is returned when a request ends with a non-2xx response.
+// Use errors.As to inspect it.
+type HTTPError struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	URL        string
+	Method     string
+	Attempts   int
+	// RequestID is the ID of the request, if enabled with SetRequestID
+	RequestID string
+	// Model is the error body decoded into the registered error model, if any
+	Model any
+	// Err is the decoded API error, if the error decoder or model produced one
+	Err error
+}
+
+func (e *HTTPError) Error() string {
+	status := fmt.Sprintf("HTTP %d", e.StatusCode)
+	if e.RequestID != "" {
+		status += " (request ID " + e.RequestID + ")"
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", status, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", status, string(e.Body))
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func newHTTPError(req *Request, resp *Response) *HTTPError {
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Headers:    http.Header(resp.Headers),
+		Body:       resp.Body,
+		URL:        req.URL,
+		Method:     req.Method,
+		RequestID:  req.ID,
+	}
+}
+
+// ErrorDecoder turns a non-2xx response into an API-specific error
+type ErrorDecoder func(resp *Response) error
+
+// errorModel maps a status range to the type error bodies are decoded into
+type errorModel struct {
+	min, max int
+	typ      reflect.Type
+}
+
+// decodeError enriches e with the error decoder and the error model
+// registered for its status code
+func (c *Client) decodeError(e *HTTPError, resp *Response) {
+	var best *errorModel
+	for i, m := range c.errorModels {
+		if e.StatusCode < m.min || e.StatusCode > m.max {
+			continue
+		}
+		if best == nil || m.max-m.min < best.max-best.min {
+			best = &c.errorModels[i]
+		}
+	}
+	if best != nil && len(resp.Body) > 0 {
+		model := reflect.New(best.typ).Interface()
+		unmarshal, ok := lookupCodec(c.decoders, resp.Header("Content-Type"))
+		if !ok {
+			unmarshal = json.Unmarshal
+		}
+		if err := unmarshal(resp.Body, model); err == nil {
+			e.Model = model
+			if err, ok := model.(error); ok {
+				e.Err = err
+			}
+		}
+	}
+	if c.errorDecoder != nil {
+		if err := c.errorDecoder(resp); err != nil {
+			e.Err = err
+		}
+	}
+}
+
+// IsRetryable reports whether err is worth retrying, using the same rules as
+// DefaultRetryPolicy: network failures, attempt timeouts, 429 and 5xx responses.
+// Cancelled contexts, open circuits, rate limits and hook failures are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableStatus(httpErr.StatusCode)
+	}
+	if errors.Is(err, ErrAttemptTimeout) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+	return isNetworkError(err)
+}
+
+// IsTimeout reports whether err is a timeout: an expired context or attempt,
+// a network timeout, or a 408/504 response
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusRequestTimeout || httpErr.StatusCode == http.StatusGatewayTimeout
+	}
+	if errors.Is(err, ErrAttemptTimeout) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsTemporary reports whether err is likely transient: timeouts, connection
+// resets or refusals, truncated responses, open circuits, rate limits and
+// 429/502/503/504 responses
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsTimeout(err) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isNetworkError reports whether err originates from the network or transport
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	var opErr *net.OpError
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.As(err, &opErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewETagCache returns a cache remembering the validators and body of every
+// GET response with an ETag or Last-Modified header, in store or an LRU cache
+// if store is nil. Each request for a remembered URL is sent as a conditional
+// GET, whatever the freshness of the response, and a 304 Not Modified returns
+// the remembered body with its original status.
+func NewETagCache(store Cache) *ResponseCache {
+	rc := NewResponseCache(store)
+	rc.alwaysRevalidate = true
+	return rc
+}
+
+// WithIfNoneMatch sends a conditional request answered with 304 Not Modified
+// while the resource still has the entity tag etag
+func WithIfNoneMatch(etag string) RequestOption {
+	return WithHeader("If-None-Match", etag)
+}
+
+// WithIfModifiedSince sends a conditional request answered with 304 Not
+// Modified unless the resource changed after t
+func WithIfModifiedSince(t time.Time) RequestOption {
+	return WithHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// succeeded reports whether a response ends the request successfully: 2xx,
+// 304 Not Modified answering a conditional request, or a redirect that
+// wasn't to be followed
+func succeeded(req *Request, resp *Response) bool {
+	if resp.StatusCode == http.StatusNotModified {
+		return hasHeader(req.Headers, "If-None-Match") || hasHeader(req.Headers, "If-Modified-Since")
+	}
+	return resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.unfollowed
+}
+
+// ETag returns the entity tag of the response, for WithIfNoneMatch
+func (r *Response) ETag() string {
+	return r.Header("ETag")
+}
+
+// LastModified returns the time of the Last-Modified header, if valid
+func (r *Response) LastModified() (time.Time, bool) {
+	t, err := http.ParseTime(r.Header("Last-Modified"))
+	return t, err == nil
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failover tracks the health of the client's base URLs. A base that fails is
+// skipped until its cool-down passes or a request to it succeeds again.
+type failover struct {
+	mu        sync.Mutex
+	bases     []*url.URL
+	downUntil map[string]time.Time
+	coolDown  time.Duration
+}
+
+func newFailover(bases []*url.URL, coolDown time.Duration) *failover {
+	return &failover{
+		bases:     bases,
+		downUntil: make(map[string]time.Time),
+		coolDown:  coolDown,
+	}
+}
+
+// target points rawURL at the base for the upcoming attempt: the first healthy
+// base in configured order, starting after the current one when the previous
+// attempt failed. The base whose URL prefixes rawURL the longest is replaced
+// as a whole, path included; URLs that left its path, like "/users" resolved
+// against ".../api", only change scheme and host. URLs outside the configured
+// bases are returned unchanged.
+func (f *failover) target(rawURL string, failed bool, breaker *CircuitBreaker) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() {
+		return rawURL
+	}
+	s := u.String()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, prefix := -1, ""
+	for i, b := range f.bases {
+		if p := basePrefix(b); len(p) > len(prefix) && hasURLPrefix(s, p) {
+			current, prefix = i, p
+		}
+	}
+	whole := current >= 0
+	if !whole {
+		for i, b := range f.bases {
+			if p := origin(b); hasURLPrefix(s, p) {
+				current, prefix = i, p
+				break
+			}
+		}
+	}
+	if current < 0 {
+		return rawURL
+	}
+
+	start := 0
+	if failed {
+		start = current + 1
+	}
+
+	now := time.Now()
+	base := f.bases[start%len(f.bases)]
+	for i := range f.bases {
+		b := f.bases[(start+i)%len(f.bases)]
+		if until, ok := f.downUntil[b.Host]; ok && now.Before(until) {
+			continue
+		}
+		if breaker != nil && breaker.State(b.Host) == StateOpen {
+			continue
+		}
+		base = b
+		break
+	}
+
+	if whole {
+		return basePrefix(base) + s[len(prefix):]
+	}
+	return origin(base) + s[len(prefix):]
+}
+
+// basePrefix returns base without a trailing slash
+func basePrefix(base *url.URL) string {
+	return strings.TrimSuffix(base.String(), "/")
+}
+
+// origin returns u without its path, query and fragment
+func origin(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}).String()
+}
+
+// hasURLPrefix reports whether s is prefix or starts with it followed by a
+// path, query or fragment
+func hasURLPrefix(s, prefix string) bool {
+	return strings.HasPrefix(s, prefix) && (len(s) == len(prefix) || strings.ContainsRune("/?#", rune(s[len(prefix)])))
+}
+
+// record marks host down after a failure and healthy again after a success
+func (f *failover) record(host string, failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if failed {
+		f.downUntil[host] = time.Now().Add(f.coolDown)
+	} else {
+		delete(f.downUntil, host)
+	}
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrPending is returned by Future.Result while the request is still running
+var ErrPending = errors.New("request still pending")
+
+// Future is the pending result of a request started with DoAsync
+type Future struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	resp   *http.Response
+	err    error
+}
+
+// DoAsync starts the request in a goroutine and returns immediately.
+// out is only safe to read once the future is done.
+func (c *Client) DoAsync(ctx context.Context, method, url string, body any, out any, headers map[string]string, opts ...RequestOption) *Future {
+	var cancel context.CancelFunc
+	if ctx == nil {
+		c.mu.RLock()
+		timeout := c.timeout
+		c.mu.RUnlock()
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	f := &Future{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go func() {
+		defer close(f.done)
+		defer cancel()
+		f.resp, f.err = c.DoRequest(ctx, method, url, body, out, headers, opts...)
+	}()
+	return f
+}
+
+// Done is closed once the request has finished
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the request has finished and returns its result
+func (f *Future) Wait() (*http.Response, error) {
+	<-f.done
+	return f.resp, f.err
+}
+
+// Result returns the result without blocking, or ErrPending while the request is running
+func (f *Future) Result() (*http.Response, error) {
+	select {
+	case <-f.done:
+		return f.resp, f.err
+	default:
+		return nil, ErrPending
+	}
+}
+
+// Cancel aborts the request; Wait then returns the context error
+func (f *Future) Cancel() {
+	f.cancel()
+}
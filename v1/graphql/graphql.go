@@ -0,0 +1,130 @@
+// Package graphql is a GraphQL client on top of muxet. It builds the request
+// envelope, unwraps data and maps GraphQL errors to Go errors.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// Client sends GraphQL operations to a single endpoint
+type Client struct {
+	c        *muxet.Client
+	endpoint string
+}
+
+// New creates a GraphQL client for endpoint, relative to the base URL of c
+func New(c *muxet.Client, endpoint string) *Client {
+	return &Client{c: c, endpoint: endpoint}
+}
+
+// Request is a GraphQL operation
+type Request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// Location is a position in the query an error refers to
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a single GraphQL error
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) == 0 {
+		return "graphql: " + e.Message
+	}
+	path := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		path[i] = fmt.Sprint(p)
+	}
+	return fmt.Sprintf("graphql: %s (at %s)", e.Message, strings.Join(path, "."))
+}
+
+// Code returns extensions.code, as set by most servers (e.g. UNAUTHENTICATED)
+func (e *Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Errors is the list of errors of a response. It is returned alongside any
+// partial data, which is still decoded into out.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return fmt.Sprintf("graphql: %d errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// Query runs a query and decodes its data into out. Queries have no side
+// effects, so they are retried like idempotent requests.
+func (g *Client) Query(ctx context.Context, query string, variables map[string]any, out any, opts ...muxet.RequestOption) error {
+	opts = append([]muxet.RequestOption{muxet.WithIdempotent()}, opts...)
+	return g.Do(ctx, Request{Query: query, Variables: variables}, out, opts...)
+}
+
+// Mutate runs a mutation and decodes its data into out
+func (g *Client) Mutate(ctx context.Context, mutation string, variables map[string]any, out any, opts ...muxet.RequestOption) error {
+	return g.Do(ctx, Request{Query: mutation, Variables: variables}, out, opts...)
+}
+
+// Do sends req and decodes the data of the response into out. GraphQL errors
+// are returned as Errors, also when the server pairs them with an HTTP error status.
+func (g *Client) Do(ctx context.Context, req Request, out any, opts ...muxet.RequestOption) error {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/graphql-response+json, application/json",
+	}
+	var resp response
+	_, err := g.c.Post(ctx, g.endpoint, req, &resp, headers, opts...)
+	if err != nil {
+		var httpErr *muxet.HTTPError
+		if !errors.As(err, &httpErr) || json.Unmarshal(httpErr.Body, &resp) != nil || len(resp.Errors) == 0 {
+			return err
+		}
+	}
+
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("graphql: failed to decode data: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// WithGzipBody gzips the request body and sets Content-Encoding: gzip
+func WithGzipBody() RequestOption {
+	return func(o *requestOptions) {
+		o.gzip = true
+	}
+}
+
+// compress gzips the body: encoded values right away, streamed bodies on
+// the fly for every attempt
+func (b *requestBody) compress() error {
+	if b.data == nil {
+		b.gzip = true
+		return nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b.data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	b.data = buf.Bytes()
+	return nil
+}
+
+// gzipStream returns a reader producing the gzipped content of rc
+func gzipStream(rc io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer rc.Close()
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, rc)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// hasHeader reports whether headers contain key in any casing
+func hasHeader(headers map[string]string, key string) bool {
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == key {
+			return true
+		}
+	}
+	return false
+}
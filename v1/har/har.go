@@ -0,0 +1,273 @@
+// Package har records the traffic of a muxet client in the HTTP Archive
+// format (HAR 1.2), to share reproductions with API vendors or inspect them
+// in browser devtools. Every attempt, retries included, becomes an entry.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// DefaultMaxBodySize is how many bytes of each body are recorded by default
+const DefaultMaxBodySize = 1 << 20
+
+// Recorder collects HAR entries from the middleware it returns
+type Recorder struct {
+	// MaxBodySize limits the recorded bytes of each body; longer bodies are cut
+	MaxBodySize int
+	// Redact lists headers whose values are replaced, by default credentials
+	// and cookies
+	Redact []string
+
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// NewRecorder returns a recorder; register it with client.Use(rec.Middleware())
+func NewRecorder() *Recorder {
+	return &Recorder{
+		MaxBodySize: DefaultMaxBodySize,
+		Redact:      []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+	}
+}
+
+// Middleware records each request passing through it
+func (r *Recorder) Middleware() muxet.Middleware {
+	return func(next muxet.HTTPDoer) muxet.HTTPDoer {
+		return muxet.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			e := &entry{start: time.Now()}
+			e.request = r.request(req)
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = &capture{ReadCloser: req.Body, rec: r, buf: &e.reqBody}
+			}
+			r.mu.Lock()
+			r.entries = append(r.entries, e)
+			r.mu.Unlock()
+
+			resp, err := next.Do(req)
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			e.wait = time.Since(e.start)
+			if err != nil {
+				e.err = err.Error()
+				return resp, err
+			}
+			e.response = r.response(resp)
+			if resp.StatusCode == http.StatusSwitchingProtocols {
+				// the body is the upgraded connection
+				return resp, nil
+			}
+			done := func() { e.receive = time.Since(e.start) - e.wait }
+			resp.Body = &capture{ReadCloser: resp.Body, rec: r, buf: &e.respBody, done: done}
+			return resp, nil
+		})
+	}
+}
+
+// Reset drops the recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// WriteTo writes the recorded entries as a HAR document
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// WriteFile writes the recorded entries as a HAR document to path
+func (r *Recorder) WriteFile(path string) error {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the recorded entries as a HAR document
+func (r *Recorder) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	doc := Log{Version: "1.2", Creator: Creator{Name: "muxet", Version: "1"}, Entries: make([]Entry, 0, len(r.entries))}
+	for _, e := range r.entries {
+		doc.Entries = append(doc.Entries, e.har())
+	}
+	r.mu.Unlock()
+	return json.MarshalIndent(struct {
+		Log Log `json:"log"`
+	}{doc}, "", "  ")
+}
+
+func (r *Recorder) request(req *http.Request) Request {
+	query := []NameValue{}
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			query = append(query, NameValue{k, v})
+		}
+	}
+	slices.SortStableFunc(query, func(a, b NameValue) int { return strings.Compare(a.Name, b.Name) })
+	header := req.Header.Clone()
+	if req.Host != "" {
+		header.Set("Host", req.Host)
+	} else {
+		header.Set("Host", req.URL.Host)
+	}
+	return Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []Cookie{},
+		Headers:     r.headers(header),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+func (r *Recorder) response(resp *http.Response) *Response {
+	return &Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []Cookie{},
+		Headers:     r.headers(resp.Header),
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+func (r *Recorder) headers(h http.Header) []NameValue {
+	headers := []NameValue{}
+	for k, vs := range h {
+		for _, v := range vs {
+			if slices.Contains(r.Redact, http.CanonicalHeaderKey(k)) {
+				v = "[REDACTED]"
+			}
+			headers = append(headers, NameValue{k, v})
+		}
+	}
+	slices.SortStableFunc(headers, func(a, b NameValue) int { return strings.Compare(a.Name, b.Name) })
+	return headers
+}
+
+// entry is a recorded exchange; its fields are guarded by rec.mu
+type entry struct {
+	start    time.Time
+	request  Request
+	response *Response
+	err      string
+	reqBody  body
+	respBody body
+	wait     time.Duration
+	receive  time.Duration
+}
+
+// body is a recorded body and its full size
+type body struct {
+	data []byte
+	size int64
+}
+
+func (e *entry) har() Entry {
+	req := e.request
+	if e.reqBody.size > 0 {
+		req.BodySize = e.reqBody.size
+		text, _ := e.reqBody.text()
+		req.PostData = &PostData{MimeType: mimeType(req.Headers), Text: text}
+	}
+	resp := Response{Status: 0, Cookies: []Cookie{}, Headers: []NameValue{}, HeadersSize: -1, BodySize: -1, Error: e.err}
+	if e.response != nil {
+		resp = *e.response
+		text, encoding := e.respBody.text()
+		resp.BodySize = e.respBody.size
+		resp.Content = Content{Size: e.respBody.size, MimeType: mimeType(resp.Headers), Text: text, Encoding: encoding}
+	}
+	return Entry{
+		StartedDateTime: e.start.Format("2006-01-02T15:04:05.000Z07:00"),
+		Time:            ms(e.wait + e.receive),
+		Request:         req,
+		Response:        resp,
+		Cache:           struct{}{},
+		Timings:         Timings{Send: 0, Wait: ms(e.wait), Receive: ms(e.receive)},
+	}
+}
+
+// text returns the recorded body as text, or base64 if it is binary
+func (b body) text() (string, string) {
+	if utf8.Valid(b.data) {
+		return string(b.data), ""
+	}
+	return base64.StdEncoding.EncodeToString(b.data), "base64"
+}
+
+func mimeType(headers []NameValue) string {
+	for _, h := range headers {
+		if http.CanonicalHeaderKey(h.Name) == "Content-Type" {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// capture records a body as it is read
+type capture struct {
+	io.ReadCloser
+	rec  *Recorder
+	buf  *body
+	done func()
+	once sync.Once
+}
+
+func (c *capture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.rec.mu.Lock()
+	c.buf.size += int64(n)
+	if keep := c.rec.MaxBodySize - len(c.buf.data); keep > 0 {
+		c.buf.data = append(c.buf.data, p[:min(n, keep)]...)
+	}
+	c.rec.mu.Unlock()
+	if err == io.EOF {
+		c.finish()
+	}
+	return n, err
+}
+
+func (c *capture) Close() error {
+	c.finish()
+	return c.ReadCloser.Close()
+}
+
+func (c *capture) finish() {
+	if c.done == nil {
+		return
+	}
+	c.once.Do(func() {
+		c.rec.mu.Lock()
+		c.done()
+		c.rec.mu.Unlock()
+	})
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// sendHedged sends req and, each time no response has arrived within the
+// hedge delay, another concurrent copy up to maxHedges extra copies. The first
+// copy to complete without a transport error wins and the rest are cancelled.
+func (c *Client) sendHedged(req *http.Request, parent context.Context) (*http.Response, *Response, error) {
+	type result struct {
+		resp    *http.Response
+		muxResp *Response
+		err     error
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	results := make(chan result, c.maxHedges+1)
+	launched, inflight := 0, 0
+	launch := func() {
+		r := req.Clone(ctx)
+		if req.GetBody != nil {
+			r.Body, _ = req.GetBody()
+		}
+		launched++
+		inflight++
+		go func() {
+			resp, muxResp, err := c.send(r, parent, false)
+			results <- result{resp, muxResp, err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	var last result
+	for {
+		select {
+		case <-timer.C:
+			if launched <= c.maxHedges {
+				c.logf(parent, slog.LevelDebug, "Hedging request",
+					[]slog.Attr{slog.String("method", req.Method), slog.String("url", req.URL.String()), slog.Int("copy", launched+1)},
+					"Hedging request: %s %s (copy %d)", req.Method, req.URL, launched+1)
+				launch()
+				timer.Reset(c.hedgeDelay)
+			}
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				return r.resp, r.muxResp, nil
+			}
+			last = r
+			if inflight == 0 {
+				return last.resp, last.muxResp, last.err
+			}
+		}
+	}
+}
+
+// hedged reports whether requests with method are eligible for hedging
+func (c *Client) hedged(method string) bool {
+	return c.maxHedges > 0 && c.hedgeDelay > 0 && method == http.MethodGet
+}
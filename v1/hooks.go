@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+)
+
+// PanicError is returned when a hook panics; the request fails instead of
+// crashing the goroutine
+type PanicError struct {
+	Value any
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("hook panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// AddBeforeRequestHook appends fn to the hooks run before each request. They
+// run in the order they were added, after the BeforeRequest hook; the first
+// error aborts the request.
+func (c *Client) AddBeforeRequestHook(fn func(*Request) error) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.beforeHooks = append(slices.Clip(c.beforeHooks), fn)
+	return c
+}
+
+// AddAfterResponseHook appends fn to the hooks run after each response,
+// error statuses included, once per attempt. They run in the order they were
+// added, after the AfterResponse hook; the first error fails the request.
+func (c *Client) AddAfterResponseHook(fn func(*Response) error) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.afterHooks = append(slices.Clip(c.afterHooks), fn)
+	return c
+}
+
+// WithBeforeHook adds a hook run before a single request, after the client's
+// before request hooks, e.g. to sign requests to one endpoint
+func WithBeforeHook(fn func(*Request) error) RequestOption {
+	return func(o *requestOptions) {
+		o.beforeHooks = append(o.beforeHooks, fn)
+	}
+}
+
+// WithAfterHook adds a hook run after each response to a single request,
+// error statuses included, after the client's after response hooks, e.g. to
+// validate it
+func WithAfterHook(fn func(*Response) error) RequestOption {
+	return func(o *requestOptions) {
+		o.afterHooks = append(o.afterHooks, fn)
+	}
+}
+
+// beforeRequest runs the BeforeRequest hook, the added hooks and those of
+// the request
+func (c *Client) beforeRequest(req *Request) error {
+	if c.BeforeRequest != nil {
+		if err := callHook(c.BeforeRequest, req); err != nil {
+			return err
+		}
+	}
+	for _, fn := range slices.Concat(c.beforeHooks, req.opts.beforeHooks) {
+		if err := callHook(fn, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterResponse runs the AfterResponse hook, the added hooks and those of
+// the request
+func (c *Client) afterResponse(req *Request, resp *Response) error {
+	if c.AfterResponse != nil {
+		if err := callHook(c.AfterResponse, resp); err != nil {
+			return err
+		}
+	}
+	for _, fn := range slices.Concat(c.afterHooks, req.opts.afterHooks) {
+		if err := callHook(fn, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onRetry runs the OnRetry hook, if any
+func (c *Client) onRetry(attempt int, req *Request, resp *Response, err error) error {
+	if c.OnRetry == nil {
+		return nil
+	}
+	return callHook(func(struct{}) error {
+		c.OnRetry(attempt, req, resp, err)
+		return nil
+	}, struct{}{})
+}
+
+// onRedirect runs the OnRedirect hook, if any
+func (c *Client) onRedirect(req *http.Request, via []*http.Request) error {
+	if c.OnRedirect == nil {
+		return nil
+	}
+	return callHook(func(struct{}) error {
+		return c.OnRedirect(req, via)
+	}, struct{}{})
+}
+
+// onError runs the OnError hook, if any. There is nothing left to fail, so a
+// panic is only logged.
+func (c *Client) onError(req *Request, resp *Response, err error) {
+	if c.OnError == nil {
+		return
+	}
+	perr := callHook(func(struct{}) error {
+		c.OnError(req, resp, err)
+		return nil
+	}, struct{}{})
+	var panicErr *PanicError
+	if errors.As(perr, &panicErr) {
+		c.logf(req.Context, slog.LevelError, "OnError hook panicked",
+			requestAttrs(req, slog.Any("panic", panicErr.Value), slog.String("stack", string(panicErr.Stack))),
+			"OnError hook panicked: %v\n%s", panicErr.Value, panicErr.Stack)
+	}
+}
+
+// callHook calls fn, turning a panic into a *PanicError
+func callHook[T any](fn func(T) error, arg T) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn(arg)
+}
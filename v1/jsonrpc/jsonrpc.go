@@ -0,0 +1,105 @@
+// Package jsonrpc is a JSON-RPC 2.0 client over HTTP on top of muxet, so calls
+// go through the client's retries, hooks and middleware.
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// Error codes defined by the specification
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrIDMismatch is returned when the response doesn't answer the request that was sent
+var ErrIDMismatch = errors.New("jsonrpc: response id does not match request id")
+
+// Client calls methods on a single JSON-RPC endpoint. Request IDs are
+// assigned from a counter, so a Client may be shared between goroutines.
+type Client struct {
+	c        *muxet.Client
+	endpoint string
+	id       atomic.Uint64
+}
+
+// New creates a JSON-RPC client for endpoint, relative to the base URL of c
+func New(c *muxet.Client, endpoint string) *Client {
+	return &Client{c: c, endpoint: endpoint}
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (code %d)", e.Message, e.Code)
+}
+
+type request struct {
+	Version string  `json:"jsonrpc"`
+	Method  string  `json:"method"`
+	Params  any     `json:"params,omitempty"`
+	ID      *uint64 `json:"id,omitempty"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// Call invokes method with params, a slice or struct/map for positional or
+// named parameters, and decodes the result into result. Error objects are
+// returned as *Error.
+func (r *Client) Call(ctx context.Context, method string, params any, result any, opts ...muxet.RequestOption) error {
+	id := r.id.Add(1)
+	var resp response
+	_, err := r.c.Post(ctx, r.endpoint, request{Version: "2.0", Method: method, Params: params, ID: &id}, &resp, headers(), opts...)
+	if err != nil {
+		// some servers pair error objects with an HTTP error status
+		var httpErr *muxet.HTTPError
+		if !errors.As(err, &httpErr) || json.Unmarshal(httpErr.Body, &resp) != nil || resp.Error == nil {
+			return err
+		}
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if string(resp.ID) != strconv.FormatUint(id, 10) {
+		return fmt.Errorf("%w: sent %d, got %s", ErrIDMismatch, id, resp.ID)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("jsonrpc: failed to decode result: %w", err)
+		}
+	}
+	return nil
+}
+
+// Notify invokes method without an ID; the server sends no response
+func (r *Client) Notify(ctx context.Context, method string, params any, opts ...muxet.RequestOption) error {
+	_, err := r.c.Post(ctx, r.endpoint, request{Version: "2.0", Method: method, Params: params}, nil, headers(), opts...)
+	return err
+}
+
+func headers() map[string]string {
+	return map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+}
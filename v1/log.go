@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// LevelLogger is a Logger taking leveled entries with structured attributes.
+// The client logs through LogAttrs instead of Logf when the logger implements
+// it: request dumps at debug level, attempts at info, failed attempts and
+// retries at warn, and requests failing for good at error.
+type LevelLogger interface {
+	Logger
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// SlogLogger adapts a *slog.Logger to LevelLogger
+type SlogLogger struct {
+	*slog.Logger
+}
+
+// NewSlogLogger returns a logger writing to l, or to slog.Default() if l is nil
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogLogger{Logger: l}
+}
+
+// Logf logs a formatted message at info level
+func (l *SlogLogger) Logf(format string, args ...any) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+// logf logs an entry, if there is a logger. Leveled loggers get msg with
+// attrs, plain ones the line formatted from format and args. Matches of the
+// redaction patterns are masked either way.
+func (c *Client) logf(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr, format string, args ...any) {
+	switch l := c.logger.(type) {
+	case nil:
+	case LevelLogger:
+		if len(c.redactPatterns) > 0 {
+			attrs = c.redactAttrs(attrs)
+		}
+		l.LogAttrs(ctx, level, msg, attrs...)
+	default:
+		l.Logf("%s", c.redactText(fmt.Sprintf(format, args...)))
+	}
+}
+
+// printf is logf falling back to the standard logger, for output that was
+// asked for explicitly
+func (c *Client) printf(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr, format string, args ...any) {
+	if c.logger == nil {
+		log.Print(c.redactText(fmt.Sprintf(format, args...)))
+		return
+	}
+	c.logf(ctx, level, msg, attrs, format, args...)
+}
+
+// redactAttrs masks the matches of the redaction patterns in string and
+// error attributes
+func (c *Client) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		switch v := a.Value.Any().(type) {
+		case string:
+			a.Value = slog.StringValue(c.redactText(v))
+		case error:
+			a.Value = slog.StringValue(c.redactText(v.Error()))
+		case []slog.Attr:
+			a.Value = slog.GroupValue(c.redactAttrs(v)...)
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// requestAttrs describes req in structured log entries
+func requestAttrs(req *Request, attrs ...slog.Attr) []slog.Attr {
+	base := []slog.Attr{slog.String("method", req.Method), slog.String("url", req.URL)}
+	if req.ID != "" {
+		base = append(base, slog.String("request_id", req.ID))
+	}
+	return append(base, attrs...)
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores the responses of a ResponseCache. Implement it to share cached
+// responses across processes, e.g. on disk or in Redis; values are opaque.
+type Cache interface {
+	// Get returns the value stored under key, if it exists and hasn't expired
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl, or until evicted if ttl is 0
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// DefaultCacheEntries is how many responses the default LRU cache holds
+const DefaultCacheEntries = 1000
+
+// LRUCache is an in-memory Cache evicting the least recently used entries
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	items      map[string]*list.Element
+}
+
+type lruItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns a cache holding up to maxEntries values
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{maxEntries: maxEntries, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*lruItem)
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return item.value, true
+}
+
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value = &lruItem{key: key, value: value, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, value: value, expires: expires})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len returns how many values are stored, expired ones included
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Clear drops every value
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *LRUCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruItem).key)
+}
@@ -5,18 +5,34 @@ import (
 	"net/http"
 )
 
-func (c *Client) Get(ctx context.Context, url string, out any, headers map[string]string) (*http.Response, error) {
-	return c.DoRequest(ctx, http.MethodGet, url, nil, out, headers)
+func (c *Client) Get(ctx context.Context, url string, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return c.DoRequest(ctx, http.MethodGet, url, nil, out, headers, opts...)
 }
 
-func (c *Client) Post(ctx context.Context, url string, body any, out any, headers map[string]string) (*http.Response, error) {
-	return c.DoRequest(ctx, http.MethodPost, url, body, out, headers)
+func (c *Client) Post(ctx context.Context, url string, body any, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return c.DoRequest(ctx, http.MethodPost, url, body, out, headers, opts...)
 }
 
-func (c *Client) Put(ctx context.Context, url string, body any, out any, headers map[string]string) (*http.Response, error) {
-	return c.DoRequest(ctx, http.MethodPut, url, body, out, headers)
+func (c *Client) Put(ctx context.Context, url string, body any, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return c.DoRequest(ctx, http.MethodPut, url, body, out, headers, opts...)
 }
 
-func (c *Client) Delete(ctx context.Context, url string, out any, headers map[string]string) (*http.Response, error) {
-	return c.DoRequest(ctx, http.MethodDelete, url, nil, out, headers)
+func (c *Client) Delete(ctx context.Context, url string, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return c.DoRequest(ctx, http.MethodDelete, url, nil, out, headers, opts...)
+}
+
+func (c *Client) Patch(ctx context.Context, url string, body any, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return c.DoRequest(ctx, http.MethodPatch, url, body, out, headers, opts...)
+}
+
+// Head returns the status and headers of url without transferring its body
+func (c *Client) Head(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*Response, error) {
+	_, resp, err := c.do(ctx, http.MethodHead, url, nil, headers, opts)
+	return resp, err
+}
+
+// Options asks which methods and features url supports, see Response.Header("Allow")
+func (c *Client) Options(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*Response, error) {
+	_, resp, err := c.do(ctx, http.MethodOptions, url, nil, headers, opts)
+	return resp, err
 }
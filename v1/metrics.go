@@ -0,0 +1,25 @@
+package v1
+
+import "time"
+
+// Metrics receives an observation for every attempt, so any metrics system
+// can be plugged in without muxet depending on it. status is 0 when the
+// attempt failed without a response and attempt is 1-based.
+type Metrics interface {
+	ObserveRequest(method, host string, status int, duration time.Duration, attempt int)
+}
+
+// MetricsFunc adapts an ordinary function to the Metrics interface
+type MetricsFunc func(method, host string, status int, duration time.Duration, attempt int)
+
+func (f MetricsFunc) ObserveRequest(method, host string, status int, duration time.Duration, attempt int) {
+	f(method, host, status, duration, attempt)
+}
+
+// SetMetrics reports every attempt to m; nil disables it
+func (c *Client) SetMetrics(m Metrics) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+	return c
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Middleware wraps an HTTPDoer, similar to http.RoundTripper chaining
+type Middleware func(next HTTPDoer) HTTPDoer
+
+// DoerFunc adapts an ordinary function to the HTTPDoer interface
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends middleware to the transport chain. The first registered
+// middleware is the outermost one and sees the request first.
+func (c *Client) Use(mw ...Middleware) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middleware = append(slices.Clip(c.middleware), mw...)
+	return c
+}
+
+// doer returns the underlying HTTPDoer wrapped by the registered middleware;
+// unfollowed is set when a redirect is returned as is
+func (c *Client) doer(unfollowed *bool) HTTPDoer {
+	return c.chain(c.withRedirects(c.client, unfollowed))
+}
+
+// chain wraps d in the registered middleware
+func (c *Client) chain(d HTTPDoer) HTTPDoer {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	return d
+}
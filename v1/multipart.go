@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Multipart is a multipart/form-data request body. Pass it as the body of
+// Post, Put or DoRequest; parts are streamed as the request is sent instead
+// of being buffered in memory.
+type Multipart struct {
+	boundary string
+	parts    []*part
+}
+
+type part struct {
+	field    string
+	filename string
+	value    string
+	path     string
+	reader   io.Reader
+	offset   int64
+	used     bool
+}
+
+// NewMultipart creates an empty multipart body with a random boundary
+func NewMultipart() *Multipart {
+	return &Multipart{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// AddField adds a form field
+func (m *Multipart) AddField(name, value string) *Multipart {
+	m.parts = append(m.parts, &part{field: name, value: value})
+	return m
+}
+
+// AddFile adds a file part. The content is read from r, or from the file at
+// path when r is nil; the base name of path is sent as the filename.
+func (m *Multipart) AddFile(field, path string, r io.Reader) *Multipart {
+	p := &part{field: field, filename: filepath.Base(path), path: path, reader: r}
+	if s, ok := r.(io.Seeker); ok {
+		p.offset, _ = s.Seek(0, io.SeekCurrent)
+	}
+	m.parts = append(m.parts, p)
+	return m
+}
+
+// ContentType returns the Content-Type header including the boundary
+func (m *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// replayable reports whether the body can be produced more than once, which
+// is the case unless a file is read from a reader that can't seek
+func (m *Multipart) replayable() bool {
+	for _, p := range m.parts {
+		if _, ok := p.reader.(io.Seeker); p.reader != nil && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// open starts writing the body into a pipe and returns its reading end
+func (m *Multipart) open() (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.write(pw))
+	}()
+	return pr, nil
+}
+
+func (m *Multipart) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+	for _, p := range m.parts {
+		if p.filename == "" {
+			if err := mw.WriteField(p.field, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(p.field), escapeQuotes(p.filename)))
+		h.Set("Content-Type", "application/octet-stream")
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if err := p.copyTo(pw); err != nil {
+			return fmt.Errorf("failed to write multipart file %q: %w", p.filename, err)
+		}
+	}
+	return mw.Close()
+}
+
+// copyTo writes the file content of p to w
+func (p *part) copyTo(w io.Writer) error {
+	if p.reader == nil {
+		f, err := os.Open(p.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	if s, ok := p.reader.(io.Seeker); ok {
+		if _, err := s.Seek(p.offset, io.SeekStart); err != nil {
+			return err
+		}
+	} else if p.used {
+		return errBodyConsumed
+	}
+	p.used = true
+	_, err := io.Copy(w, p.reader)
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
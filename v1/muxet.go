@@ -1,13 +1,19 @@
 package v1
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,6 +24,14 @@ type Request struct {
 	Headers map[string]string
 	Body    any
 	Context context.Context
+	// ID is the request ID, if enabled with SetRequestID
+	ID string
+
+	opts *requestOptions
+	// encoders are the client's, for BodyBytes
+	encoders map[string]Encoder
+	// response is set by Respond
+	response *Response
 }
 
 // Response net/http wrapper passed to hooks
@@ -26,12 +40,29 @@ type Response struct {
 	Headers    map[string][]string
 	Body       []byte
 	Raw        *http.Response
+	// Stream is the unread body of a streamed response (see WithStreamBody);
+	// Body is nil in that case and the caller must close Stream
+	Stream io.ReadCloser
+	// RequestID is the ID of the request, if enabled with SetRequestID
+	RequestID string
+	// Timings breaks down the duration of the attempt
+	Timings Timings
+	// Redirects lists the redirects followed to get the response, oldest first
+	Redirects []Redirect
+
+	// unfollowed is set on a redirect returned as is, see SetFollowRedirects
+	unfollowed bool
 }
 
 func (r *Response) JSON(out any) error {
 	return json.Unmarshal(r.Body, out)
 }
 
+// XML decodes the body as XML into out
+func (r *Response) XML(out any) error {
+	return xml.Unmarshal(r.Body, out)
+}
+
 // Logger logger interface
 type Logger interface {
 	Logf(format string, args ...any)
@@ -42,40 +73,165 @@ type HTTPDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// Client is a reusable HTTP client with timeouts, base URL, retry logic, and hooks
+// Client is a reusable HTTP client with timeouts, base URL, retry logic, and hooks.
+// It is safe for concurrent use, setters included: a request works on a
+// snapshot of the configuration taken when it starts. The exported fields are
+// not synchronized; assign them only before the client is shared and use the
+// setters afterwards.
 type Client struct {
-	client        HTTPDoer
-	headers       map[string]string
-	timeout       time.Duration
-	BaseURL       string
-	logger        Logger
-	maxRetries    int
-	backoff       time.Duration
-	BeforeRequest func(*Request) error
-	AfterResponse func(*Response) error
-}
-
-// NewClient creates a new HTTP client with default settings
-func NewClient() *Client {
-	return &Client{
-		client:     &http.Client{},
-		headers:    make(map[string]string),
-		timeout:    5 * time.Second,
-		maxRetries: 0,
-		backoff:    0,
-	}
-}
-
-func (c *Client) DoRequest(ctx context.Context, method, rawURL string, body any, out any, headers map[string]string) (*http.Response, error) {
-	if ctx == nil {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(context.Background(), c.timeout)
-		defer cancel()
+	// mu guards the configuration; requests work on a snapshot of it
+	mu               *sync.RWMutex
+	client           HTTPDoer
+	middleware       []Middleware
+	headers          map[string]string
+	queryParams      url.Values
+	pathParams       map[string]string
+	timeout          time.Duration
+	BaseURL          string
+	logger           Logger
+	maxRetries       int
+	backoff          Backoff
+	retryPolicy      RetryPolicy
+	maxRetryAfter    time.Duration
+	maxElapsed       time.Duration
+	attemptTimeout   time.Duration
+	breaker          *CircuitBreaker
+	hedgeDelay       time.Duration
+	maxHedges        int
+	failover         *failover
+	failoverCoolDown time.Duration
+	rateLimit        *rateLimit
+	throttle         *throttle
+	dedup            *flightGroup
+	concurrency      *concurrencyLimit
+	errorDecoder     ErrorDecoder
+	errorModels      []errorModel
+	retryBudget      *RetryBudget
+	defaultOpts      []RequestOption
+	compressRequests bool
+	debug            bool
+	curlOnError      bool
+	requestIDHeader  string
+	metrics          Metrics
+	slowThreshold    time.Duration
+	onSlowRequest    func(SlowRequest)
+	audit            AuditSink
+	clock            Clock
+	redactHeaders    []string
+	redactFields     [][]string
+	redactPatterns   []*regexp.Regexp
+	maxLoggedBody    int
+	joinMode         URLJoinMode
+	maxRedirects     int
+	followRedirects  bool
+	redirectPolicy   RedirectPolicy
+	keepCredentials  bool
+	stripHeaders     []string
+	proxyProvider    ProxyProvider
+	baseSocket       bool
+	encoders         map[string]Encoder
+	decoders         map[string]Decoder
+	BeforeRequest    func(*Request) error
+	AfterResponse    func(*Response) error
+	beforeHooks      []func(*Request) error
+	afterHooks       []func(*Response) error
+	// OnRetry is called before each retry; attempt is the 1-based number of the
+	// attempt that failed. Changes to req apply to the next attempt.
+	OnRetry func(attempt int, req *Request, resp *Response, err error)
+	// OnError is called once when a request fails for good, after its retries;
+	// resp is the last response, if any
+	OnError func(req *Request, resp *Response, err error)
+	// OnRedirect is called before following each redirect, see SetOnRedirectHook
+	OnRedirect func(req *http.Request, via []*http.Request) error
+}
+
+// NewClient creates a new HTTP client with default settings, changed by opts
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		mu:               &sync.RWMutex{},
+		client:           &http.Client{Transport: newTransport()},
+		headers:          make(map[string]string),
+		queryParams:      make(url.Values),
+		pathParams:       make(map[string]string),
+		timeout:          5 * time.Second,
+		maxRetries:       0,
+		backoff:          ExponentialBackoff{},
+		retryPolicy:      DefaultRetryPolicy,
+		maxRetryAfter:    time.Minute,
+		failoverCoolDown: 30 * time.Second,
+		maxLoggedBody:    defaultMaxLoggedBody,
+		clock:            realClock{},
+		maxRedirects:     DefaultMaxRedirects,
+		followRedirects:  true,
+		throttle:         &throttle{},
+		concurrency:      &concurrencyLimit{},
+		encoders: map[string]Encoder{
+			"application/xml": xml.Marshal,
+			"text/xml":        xml.Marshal,
+		},
+		decoders: map[string]Decoder{
+			"application/xml": xml.Unmarshal,
+			"text/xml":        xml.Unmarshal,
+		},
 	}
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+	return c
+}
 
-	fullURL, err := c.resolveURL(rawURL)
+func (c *Client) DoRequest(ctx context.Context, method, rawURL string, body any, out any, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	resp, muxResp, err := c.do(ctx, method, rawURL, body, headers, opts)
 	if err != nil {
-		return nil, err
+		return resp, err
+	}
+
+	if err := c.decode(muxResp, out); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Do sends a prepared request through the client's retries, hooks, logging
+// and middleware. Header values, trailers and Host of req are kept; its body
+// is replayed for retries if req.GetBody is set. Client default headers and
+// query parameters apply, and relative URLs are resolved against the base URL.
+func (c *Client) Do(req *http.Request, opts ...RequestOption) (*Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	var body any
+	if req.Body != nil && req.Body != http.NoBody {
+		body = preparedBody{req}
+	}
+	headers := make(map[string]string, len(req.Header))
+	for k := range req.Header {
+		headers[k] = req.Header.Get(k)
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *requestOptions) { o.raw = req })
+	_, resp, err := c.do(req.Context(), req.Method, req.URL.String(), body, headers, opts)
+	return resp, err
+}
+
+// do prepares the request, runs the before request hooks and executes it with retries
+func (c *Client) do(ctx context.Context, method, rawURL string, body any, headers map[string]string, opts []RequestOption) (resp *http.Response, muxResp *Response, err error) {
+	c, ro := c.snapshot(opts)
+
+	if ctx == nil || ro.timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer func() {
+			// a streamed body keeps the context alive until it is closed
+			if muxResp != nil && muxResp.Stream != nil {
+				muxResp.Stream = onClose(muxResp.Stream, cancel)
+				resp.Body = muxResp.Stream
+			} else {
+				cancel()
+			}
+		}()
 	}
 
 	// Merge headers
@@ -86,109 +242,399 @@ func (c *Client) DoRequest(ctx context.Context, method, rawURL string, body any,
 	for k, v := range headers {
 		hdr[k] = v
 	}
+	for k, v := range ro.headers {
+		hdr[k] = v
+	}
+	addCookies(hdr, ro.cookies)
 
 	muxReq := &Request{
-		Method:  method,
-		URL:     fullURL,
-		Headers: hdr,
-		Body:    body,
-		Context: ctx,
+		Method:   method,
+		URL:      rawURL,
+		Headers:  hdr,
+		Body:     body,
+		Context:  ctx,
+		opts:     ro,
+		encoders: c.encoders,
 	}
-
-	if c.BeforeRequest != nil {
-		if err := c.BeforeRequest(muxReq); err != nil {
-			return nil, fmt.Errorf("before request hook failed: %w", err)
+	if c.requestIDHeader != "" {
+		c.assignRequestID(muxReq)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		errText := c.redactError(err)
+		c.logf(muxReq.Context, slog.LevelError, "Request failed", requestAttrs(muxReq, slog.String("error", errText)),
+			"Giving up on %s %s: %s", muxReq.Method, muxReq.URL, errText)
+		if c.curlOnError {
+			cmd := muxReq.curl(c)
+			c.printf(muxReq.Context, slog.LevelError, "Failed request as curl", requestAttrs(muxReq, slog.String("curl", cmd)),
+				"Failed request as curl: %s", cmd)
 		}
+		c.onError(muxReq, muxResp, err)
+	}()
+
+	if muxReq.URL, err = c.requestURL(rawURL, ro); err != nil {
+		return nil, nil, err
 	}
 
-	var origBody []byte
-	if muxReq.Body != nil {
-		origBody, err = json.Marshal(muxReq.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal body: %w", err)
+	if err := c.beforeRequest(muxReq); err != nil {
+		return nil, nil, fmt.Errorf("before request hook failed: %w", err)
+	}
+	if muxReq.response != nil {
+		return c.respond(muxReq)
+	}
+
+	enc, _ := lookupCodec(c.encoders, headerValue(muxReq.Headers, "Content-Type"))
+	reqBody, err := newRequestBody(muxReq.Body, enc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reqBody != nil && (ro.gzip || c.compressRequests) && !hasHeader(muxReq.Headers, "Content-Encoding") {
+		if err := reqBody.compress(); err != nil {
+			return nil, nil, fmt.Errorf("failed to compress body: %w", err)
 		}
+		muxReq.Headers["Content-Encoding"] = "gzip"
+	}
+	if c.audit != nil {
+		start := time.Now()
+		defer func() { c.recordAudit(muxReq, reqBody, muxResp, err, start) }()
 	}
 
+	if c.dedup != nil && muxReq.Method == http.MethodGet && muxReq.Body == nil && !ro.stream {
+		return c.dedup.do(flightKey(muxReq, c.requestIDHeader), func() (*http.Response, *Response, error) {
+			return c.execute(muxReq, reqBody)
+		})
+	}
+	return c.execute(muxReq, reqBody)
+}
+
+// execute runs the attempt loop with retries, backoff and the OnRetry hook
+func (c *Client) execute(muxReq *Request, body *requestBody) (*http.Response, *Response, error) {
+	if c.retryBudget != nil {
+		c.retryBudget.deposit()
+	}
+	retryable := (muxReq.opts.idempotent || isIdempotent(muxReq.Method, muxReq.Headers)) && body.replayable()
+
+	start := c.clock.Now()
 	var resp *http.Response
+	var lastResp *Response
 	var lastErr error
+	attempts := 0
+	var delay time.Duration
+	if c.slowThreshold > 0 {
+		defer func() { c.reportSlow(muxReq, lastResp, attempts, c.clock.Now().Sub(start)) }()
+	}
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		var reqBody io.Reader
-		if muxReq.Body != nil {
-			reqBody = bytes.NewReader(origBody)
+		attempts++
+		if c.failover != nil {
+			muxReq.URL = c.failover.target(muxReq.URL, attempt > 0, c.breaker)
 		}
 
-		req, err := http.NewRequestWithContext(muxReq.Context, muxReq.Method, muxReq.URL, reqBody)
+		var muxResp *Response
+		var err error
+		resp, muxResp, err = c.roundTrip(muxReq, body, attempt)
+		lastResp = muxResp
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
+			var fatal *fatalError
+			if errors.As(err, &fatal) {
+				return resp, muxResp, fatal.err
+			}
+			lastErr = err
+			c.logf(muxReq.Context, slog.LevelWarn, "Attempt failed", requestAttrs(muxReq, slog.Int("attempt", attempt+1), slog.Any("error", err)),
+				"Request failed: %v%s", err, logRequestID(muxReq))
+		} else {
+			if succeeded(muxReq, muxResp) {
+				// a 304 answering a conditional request has no body to stream
+				if muxReq.opts.stream && muxResp.Stream == nil {
+					streamBuffered(resp, muxResp)
+				}
+				return resp, muxResp, nil
+			}
 
-		for k, v := range muxReq.Headers {
-			req.Header.Set(k, v)
+			lastErr = newHTTPError(muxReq, muxResp)
 		}
 
-		if muxReq.Body != nil && req.Header.Get("Content-Type") == "" {
-			req.Header.Set("Content-Type", "application/json")
+		if attempt == c.maxRetries || !retryable || c.retryPolicy == nil || !c.retryPolicy(muxResp, err) {
+			break
+		}
+		if c.backoff != nil {
+			delay = c.backoff.Next(attempt, delay)
+		}
+		if d, ok := retryAfter(muxResp, c.clock.Now()); ok {
+			delay = min(d, c.maxRetryAfter)
+		}
+		if c.maxElapsed > 0 && c.clock.Now().Sub(start)+delay > c.maxElapsed {
+			break
+		}
+		if c.retryBudget != nil && !c.retryBudget.withdraw() {
+			c.logf(muxReq.Context, slog.LevelWarn, "Retry budget exhausted", requestAttrs(muxReq),
+				"Retry budget exhausted, giving up")
+			break
 		}
+		if err := c.onRetry(attempt+1, muxReq, muxResp, lastErr); err != nil {
+			return resp, muxResp, fmt.Errorf("retry hook failed: %w", err)
+		}
+		c.logf(muxReq.Context, slog.LevelWarn, "Retrying request",
+			requestAttrs(muxReq, slog.Int("attempt", attempt+2), slog.Duration("delay", delay), slog.String("error", c.redactError(lastErr))),
+			"Retrying %s %s in %s (attempt %d)%s", muxReq.Method, muxReq.URL, delay, attempt+2, logRequestID(muxReq))
+		if err := c.clock.Sleep(muxReq.Context, delay); err != nil {
+			lastErr = err
+			break
+		}
+	}
 
-		if c.logger != nil {
-			c.logger.Logf("Request: %s %s (attempt %d)", muxReq.Method, muxReq.URL, attempt+1)
+	var httpErr *HTTPError
+	if errors.As(lastErr, &httpErr) {
+		httpErr.Attempts = attempts
+		c.decodeError(httpErr, lastResp)
+	}
+	if muxReq.ID != "" {
+		return resp, lastResp, fmt.Errorf("request %s failed after %d attempts: %w", muxReq.ID, attempts, lastErr)
+	}
+	return resp, lastResp, fmt.Errorf("request failed after %d attempts: %w", attempts, lastErr)
+}
+
+// fatalError marks failures that end the request immediately instead of being retried
+type fatalError struct {
+	err error
+}
+
+func (e *fatalError) Error() string { return e.err.Error() }
+
+// roundTrip performs a single attempt and buffers the response body, unless
+// it is streamed. The attempt is bounded by the attempt timeout, if any, on top
+// of the request context; for streamed bodies this includes reading the body.
+func (c *Client) roundTrip(muxReq *Request, body *requestBody, attempt int) (*http.Response, *Response, error) {
+	// cleanup runs when the attempt ends, or when a streamed body is closed
+	var cleanup []func()
+	defer func() {
+		for _, fn := range cleanup {
+			fn()
 		}
+	}()
+
+	ctx := context.WithValue(muxReq.Context, attemptKey{}, attempt+1)
+	if muxReq.opts.cache != (cacheOptions{}) {
+		ctx = context.WithValue(ctx, cacheOptionsKey{}, muxReq.opts.cache)
+	}
+	if c.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.attemptTimeout)
+		cleanup = append(cleanup, cancel)
+	}
+
+	req, err := newHTTPRequest(ctx, muxReq)
+	if err != nil {
+		return nil, nil, &fatalError{fmt.Errorf("failed to create request: %w", err)}
+	}
+	if req, err = c.chooseProxy(muxReq, req); err != nil {
+		return nil, nil, &fatalError{err}
+	}
 
-		resp, err = c.client.Do(req)
+	if body != nil {
+		req.Body, req.ContentLength, err = body.open()
 		if err != nil {
-			lastErr = err
-			if c.logger != nil {
-				c.logger.Logf("Request failed: %v", err)
+			return nil, nil, &fatalError{err}
+		}
+		req.GetBody = body.getBody()
+		if fn := muxReq.opts.uploadProgress; fn != nil {
+			total := req.ContentLength
+			if total == 0 {
+				total = -1
 			}
-			time.Sleep(c.backoff * time.Duration(1<<attempt))
-			continue
+			req.Body = &progressReader{ReadCloser: req.Body, fn: fn, total: total}
 		}
+	}
 
-		rawBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return resp, fmt.Errorf("failed to read response body: %w", err)
+	for k, v := range muxReq.Headers {
+		// keep repeated values of a prepared request unless they were changed
+		if req.Header.Get(k) != v {
+			req.Header.Set(k, v)
 		}
+	}
 
-		muxResp := &Response{
-			StatusCode: resp.StatusCode,
-			Headers:    resp.Header.Clone(),
-			Body:       rawBody,
-			Raw:        resp,
-		}
+	if body != nil && body.contentType != "" && (body.fixedType || req.Header.Get("Content-Type") == "") {
+		req.Header.Set("Content-Type", body.contentType)
+	}
 
-		if c.AfterResponse != nil {
-			if err := c.AfterResponse(muxResp); err != nil {
-				return resp, fmt.Errorf("after response hook failed: %w", err)
-			}
+	c.logf(muxReq.Context, slog.LevelInfo, "Request", requestAttrs(muxReq, slog.Int("attempt", attempt+1)),
+		"Request: %s %s (attempt %d)%s", muxReq.Method, muxReq.URL, attempt+1, logRequestID(muxReq))
+
+	release, err := c.admit(ctx, req.URL.Host)
+	if err != nil {
+		return nil, nil, &fatalError{err}
+	}
+	cleanup = append(cleanup, release)
+
+	if c.debug {
+		c.dumpRequest(req, body)
+	}
+	start := time.Now()
+	stream := muxReq.opts.stream
+	var resp *http.Response
+	var muxResp *Response
+	var timing *timingTrace
+	if c.hedged(req.Method) && !stream && (body == nil || req.GetBody != nil) {
+		resp, muxResp, err = c.sendHedged(req, muxReq.Context)
+	} else {
+		timing = &timingTrace{start: start}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.clientTrace()))
+		resp, muxResp, err = c.send(req, muxReq.Context, stream)
+	}
+	elapsed := time.Since(start)
+	if c.debug {
+		c.dumpResponse(req, resp, muxResp, err, elapsed)
+	}
+	if c.metrics != nil {
+		status := 0
+		if muxResp != nil {
+			status = muxResp.StatusCode
 		}
+		c.metrics.ObserveRequest(req.Method, req.URL.Host, status, elapsed, attempt+1)
+	}
+	if muxResp != nil {
+		muxResp.RequestID = muxReq.ID
+		if timing != nil {
+			muxResp.Timings = timing.result(elapsed)
+		}
+	}
+	if muxResp != nil && muxResp.Stream != nil {
+		muxResp.Stream = onClose(muxResp.Stream, cleanup...)
+		resp.Body = muxResp.Stream
+		cleanup = nil
+	}
+	if c.breaker != nil {
+		c.breaker.record(req.URL.Host, muxResp, err)
+	}
+	if c.failover != nil {
+		c.failover.record(req.URL.Host, hostFailure(muxResp, err))
+	}
+	if muxResp != nil {
+		c.throttle.observe(req.URL.Host, muxResp.Headers)
+	}
+	if err != nil {
+		return resp, muxResp, err
+	}
 
-		defer resp.Body.Close()
+	if err := c.afterResponse(muxReq, muxResp); err != nil {
+		return resp, muxResp, &fatalError{fmt.Errorf("after response hook failed: %w", err)}
+	}
 
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
-			time.Sleep(c.backoff * time.Duration(1<<attempt))
-			continue
+	return resp, muxResp, nil
+}
+
+// newHTTPRequest creates the request of an attempt, starting from a copy of
+// the prepared request passed to Do, if any
+func newHTTPRequest(ctx context.Context, muxReq *Request) (*http.Request, error) {
+	raw := muxReq.opts.raw
+	if raw == nil {
+		return http.NewRequestWithContext(ctx, muxReq.Method, muxReq.URL, nil)
+	}
+	req := raw.Clone(ctx)
+	req.Method = muxReq.Method
+	req.Body, req.GetBody, req.ContentLength = nil, nil, 0
+	if req.URL.String() != muxReq.URL {
+		u, err := url.Parse(muxReq.URL)
+		if err != nil {
+			return nil, err
 		}
+		if raw.Host == raw.URL.Host {
+			req.Host = ""
+		}
+		req.URL = u
+	}
+	return req, nil
+}
 
-		if out != nil {
-			if s, ok := out.(*string); ok {
-				*s = string(rawBody)
-			} else {
-				// decode json from rawBody bytes instead of resp.Body
-				if err := json.Unmarshal(rawBody, out); err != nil {
-					return resp, fmt.Errorf("failed to decode response: %w", err)
-				}
-			}
+// admit waits until a request to host may hit the wire: a free concurrency
+// slot, a rate limit token, quota headroom and a circuit that isn't open.
+// The returned func frees the concurrency slot again.
+func (c *Client) admit(ctx context.Context, host string) (func(), error) {
+	release, err := c.concurrency.acquire(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if c.rateLimit != nil {
+		if err := c.rateLimit.wait(ctx, host); err != nil {
+			release()
+			return nil, err
 		}
+	}
+	if err := c.throttle.wait(ctx, host, c.maxRetryAfter); err != nil {
+		release()
+		return nil, err
+	}
+	if c.breaker != nil {
+		if err := c.breaker.allow(host); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
 
-		return resp, nil
+// send hands req to the transport chain and buffers the response body.
+// With stream set, the body of a 2xx response or of a redirect that isn't
+// followed is left unread.
+func (c *Client) send(req *http.Request, parent context.Context, stream bool) (*http.Response, *Response, error) {
+	var unfollowed bool
+	resp, err := c.doer(&unfollowed).Do(req)
+	var hookErr *redirectHookError
+	if errors.As(err, &hookErr) {
+		return nil, nil, &fatalError{fmt.Errorf("redirect hook failed: %w", hookErr.err)}
+	}
+	if errors.Is(err, ErrTooManyRedirects) {
+		return nil, nil, &fatalError{err}
+	}
+	if err != nil {
+		if parent.Err() == nil {
+			c.proxyFailed(req, err)
+		}
+		return nil, nil, attemptError(req.Context(), parent, err)
+	}
+
+	if stream && (resp.StatusCode >= 200 && resp.StatusCode < 300 || unfollowed) {
+		return resp, &Response{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+			Raw:        resp,
+			Stream:     resp.Body,
+			Redirects:  redirectChain(resp),
+			unfollowed: unfollowed,
+		}, nil
+	}
+
+	rawBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return resp, nil, &fatalError{fmt.Errorf("failed to read response body: %w", attemptError(req.Context(), parent, err))}
 	}
 
-	return resp, fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+	return resp, &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header.Clone(),
+		Body:       rawBody,
+		Raw:        resp,
+		Redirects:  redirectChain(resp),
+		unfollowed: unfollowed,
+	}, nil
 }
 
+// URLJoinMode controls how request URLs are joined with the base URL
+type URLJoinMode int
+
+const (
+	// JoinResolve resolves request URLs against the base URL as a browser
+	// would (RFC 3986): "/users" replaces the base path, and "users" replaces
+	// its last segment unless the base URL ends with a slash
+	JoinResolve URLJoinMode = iota
+	// JoinAppend appends request paths to the base path, so a base URL of
+	// https://api.example.com/v2 and "/users" give https://api.example.com/v2/users
+	JoinAppend
+)
+
 func (c *Client) resolveURL(input string) (string, error) {
 	u, err := url.Parse(input)
 	if err != nil {
@@ -201,5 +647,27 @@ func (c *Client) resolveURL(input string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("invalid base URL: %w", err)
 	}
+	if c.joinMode == JoinAppend && u.Host == "" {
+		return appendURL(base, u).String(), nil
+	}
 	return base.ResolveReference(u).String(), nil
 }
+
+// appendURL appends the path of ref to the path of base with exactly one
+// slash in between, keeping a trailing slash of ref. The query of ref is
+// added to that of base.
+func appendURL(base, ref *url.URL) *url.URL {
+	u := *base
+	u.Fragment, u.RawFragment = ref.Fragment, ref.RawFragment
+	if ref.Path != "" {
+		u.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.TrimPrefix(ref.Path, "/")
+		u.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + "/" + strings.TrimPrefix(ref.EscapedPath(), "/")
+	}
+	switch {
+	case u.RawQuery == "":
+		u.RawQuery = ref.RawQuery
+	case ref.RawQuery != "":
+		u.RawQuery += "&" + ref.RawQuery
+	}
+	return &u
+}
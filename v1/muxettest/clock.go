@@ -0,0 +1,63 @@
+package muxettest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FakeClock is a muxet.Clock for deterministic retry tests. Its time only
+// moves when it sleeps or is advanced, and Sleep returns at once, recording
+// the delay:
+//
+//	clock := muxettest.NewFakeClock(time.Time{})
+//	client.SetClock(clock)
+//	...
+//	clock.Sleeps() // [100ms 200ms 400ms]
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewFakeClock returns a clock set to now, or to 2000-01-01 UTC if now is zero
+func NewFakeClock(now time.Time) *FakeClock {
+	if now.IsZero() {
+		now = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep records d and advances the clock by it, unless ctx is done
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(max(d, 0))
+	return nil
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleeps returns the delays slept so far, in order
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.sleeps)
+}
@@ -0,0 +1,217 @@
+package muxettest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TB is the part of testing.TB the assertions use
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Expectation is a request the code under test is expected to send, with
+// the answer to give, registered with MockDoer.On:
+//
+//	mock.On(http.MethodPost, "/users").
+//		WithJSONBody(map[string]any{"name": "Ada"}).
+//		Return(http.StatusCreated, User{ID: 42, Name: "Ada"}).
+//		Times(2)
+//	...
+//	mock.AssertExpectations(t)
+type Expectation struct {
+	mock     *MockDoer
+	method   string
+	url      string
+	matchers []func(req *http.Request, body []byte) bool
+	// optional stubs don't have to be called
+	optional bool
+	// times limits the calls matched; 0 is unlimited
+	times int
+	calls int
+
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// On expects a request with the method and URL, matched like a Stub's; the
+// other expectations registered before it are tried first. It responds 200
+// with an empty body unless told otherwise.
+func (m *MockDoer) On(method, url string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation{mock: m, method: method, url: url, header: http.Header{}}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// WithHeader matches requests with the header value
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	return e.match(func(req *http.Request, _ []byte) bool {
+		return req.Header.Get(key) == value
+	})
+}
+
+// WithBody matches requests sending exactly this body
+func (e *Expectation) WithBody(body string) *Expectation {
+	return e.match(func(_ *http.Request, got []byte) bool {
+		return string(got) == body
+	})
+}
+
+// WithJSONBody matches requests whose JSON body equals want once both are
+// encoded, or satisfies want if it is a func(any) bool, called with the
+// decoded body
+func (e *Expectation) WithJSONBody(want any) *Expectation {
+	if fn, ok := want.(func(any) bool); ok {
+		return e.match(func(_ *http.Request, got []byte) bool {
+			var v any
+			return json.Unmarshal(got, &v) == nil && fn(v)
+		})
+	}
+	data, err := json.Marshal(want)
+	return e.match(func(_ *http.Request, got []byte) bool {
+		return err == nil && matchBody(data, got)
+	})
+}
+
+// WithMatch matches requests for which fn returns true
+func (e *Expectation) WithMatch(fn func(req *http.Request, body []byte) bool) *Expectation {
+	return e.match(fn)
+}
+
+// Return responds with the status and body: strings and byte slices are
+// sent as they are, other values encoded as JSON
+func (e *Expectation) Return(status int, body any) *Expectation {
+	var data []byte
+	switch b := body.(type) {
+	case nil:
+	case string:
+		data = []byte(b)
+	case []byte:
+		data = b
+	default:
+		var err error
+		if data, err = json.Marshal(b); err != nil {
+			panic(fmt.Sprintf("muxettest: failed to encode response body: %v", err))
+		}
+	}
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	e.status = status
+	e.body = data
+	if data != nil && !isText(body) && e.header.Get("Content-Type") == "" {
+		e.header.Set("Content-Type", "application/json")
+	}
+	return e
+}
+
+// ReturnHeader adds a header to the response
+func (e *Expectation) ReturnHeader(key, value string) *Expectation {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	e.header.Add(key, value)
+	return e
+}
+
+// ReturnError fails the request with err instead of responding
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	e.err = err
+	return e
+}
+
+// Times expects exactly n calls; further requests fall through to the next
+// expectations. Without it, any number of calls but at least one is expected.
+func (e *Expectation) Times(n int) *Expectation {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	e.times = n
+	return e
+}
+
+// Once is Times(1)
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Calls returns how many requests the expectation answered
+func (e *Expectation) Calls() int {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	return e.calls
+}
+
+func (e *Expectation) match(fn func(req *http.Request, body []byte) bool) *Expectation {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	e.matchers = append(e.matchers, fn)
+	return e
+}
+
+// matches reports whether e answers the request; the mock lock is held
+func (e *Expectation) matches(req *http.Request, body []byte) bool {
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	if e.method != "" && !strings.EqualFold(e.method, req.Method) {
+		return false
+	}
+	if e.url != "" && !matchURL(e.url, req.URL) {
+		return false
+	}
+	for _, fn := range e.matchers {
+		if !fn(req, body) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) String() string {
+	s := strings.TrimSpace(e.method + " " + e.url)
+	if s == "" {
+		s = "any request"
+	}
+	return s
+}
+
+// AssertExpectations fails t for every expectation not called as expected
+// and every request nothing matched. Call it at the end of the test, e.g.
+// with t.Cleanup.
+func (m *MockDoer) AssertExpectations(t TB) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ok := true
+	for _, e := range m.expectations {
+		switch {
+		case e.optional:
+		case e.times > 0 && e.calls != e.times:
+			t.Errorf("muxettest: expected %s to be called %d times, got %d", e, e.times, e.calls)
+			ok = false
+		case e.times == 0 && e.calls == 0:
+			t.Errorf("muxettest: expected %s to be called", e)
+			ok = false
+		}
+	}
+	for _, req := range m.unmatched {
+		t.Errorf("muxettest: unexpected request %s", req)
+		ok = false
+	}
+	return ok
+}
+
+func isText(body any) bool {
+	switch body.(type) {
+	case string, []byte:
+		return true
+	}
+	return false
+}
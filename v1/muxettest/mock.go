@@ -0,0 +1,167 @@
+// Package muxettest helps testing code built on muxet clients without real
+// servers:
+//
+//	mock := muxettest.NewMockDoer().Add(muxettest.Stub{
+//		Method: http.MethodGet, URL: "/users/42",
+//		Status: http.StatusOK, RespBody: `{"id":42}`,
+//	})
+//	client := muxet.NewClient().SetBaseURL("https://api.example.com").SetHTTPClient(mock)
+package muxettest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNoMatch is returned for requests no stub matches
+var ErrNoMatch = errors.New("muxettest: no stub matches the request")
+
+// Stub is a canned answer to the requests it matches
+type Stub struct {
+	// Method matches any method if empty
+	Method string
+	// URL is the full URL to match, or only its path and query if it starts
+	// with "/"; empty matches any URL. Without a query, any query matches;
+	// with one, the request needs the same parameters in any order.
+	URL string
+	// Body matches requests sending exactly this body, or an equal JSON
+	// document; empty matches any body
+	Body string
+
+	// Status of the response, 200 if unset
+	Status     int
+	RespHeader http.Header
+	RespBody   string
+	// Err fails the request with this error instead of responding
+	Err error
+}
+
+// MockDoer is an HTTPDoer answering requests with stubs and expectations,
+// for muxet.Client.SetHTTPClient. The first matching one wins; unmatched
+// requests fail with ErrNoMatch.
+type MockDoer struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	unmatched    []string
+}
+
+// NewMockDoer returns a MockDoer without stubs
+func NewMockDoer() *MockDoer {
+	return &MockDoer{}
+}
+
+// Add registers stubs after the existing ones. Unlike expectations, they
+// don't have to be called.
+func (m *MockDoer) Add(stubs ...Stub) *MockDoer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range stubs {
+		e := &Expectation{mock: m, method: s.Method, url: s.URL, optional: true,
+			status: s.Status, header: s.RespHeader.Clone(), body: []byte(s.RespBody), err: s.Err}
+		if s.Body != "" {
+			want := []byte(s.Body)
+			e.matchers = append(e.matchers, func(_ *http.Request, body []byte) bool { return matchBody(want, body) })
+		}
+		m.expectations = append(m.expectations, e)
+	}
+	return m
+}
+
+// Reset removes all stubs and expectations and forgets unmatched requests
+func (m *MockDoer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = nil
+	m.unmatched = nil
+}
+
+// Do answers req with the first matching stub or expectation
+func (m *MockDoer) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.matches(req, body) {
+			e.calls++
+			if e.err != nil {
+				return nil, e.err
+			}
+			return Response(req, e.status, e.header, string(e.body)), nil
+		}
+	}
+	m.unmatched = append(m.unmatched, req.Method+" "+req.URL.String())
+	return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL)
+}
+
+// Response builds a response to req, e.g. for custom doers in tests
+func Response(req *http.Request, status int, header http.Header, body string) *http.Response {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readBody reads the body of req and puts it back for later readers
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("muxettest: failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// matchURL reports whether u matches pattern, a full URL or a path
+func matchURL(pattern string, u *url.URL) bool {
+	p, err := url.Parse(pattern)
+	if err != nil {
+		return false
+	}
+	if !strings.HasPrefix(pattern, "/") && (p.Scheme != u.Scheme || p.Host != u.Host) {
+		return false
+	}
+	if p.Path != u.Path {
+		return false
+	}
+	return p.RawQuery == "" || reflect.DeepEqual(p.Query(), u.Query())
+}
+
+// matchBody reports whether body equals want, byte for byte or as JSON
+func matchBody(want, body []byte) bool {
+	if bytes.Equal(want, body) {
+		return true
+	}
+	var w, b any
+	if json.Unmarshal(want, &w) != nil || json.Unmarshal(body, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(w, b)
+}
@@ -0,0 +1,147 @@
+package muxettest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// Recorder captures every request a client sends, retries included, to
+// assert on what went out:
+//
+//	rec := muxettest.NewRecorder()
+//	client.Use(rec.Middleware())
+//	...
+//	rec.AssertCalled(t, http.MethodPost, "/users")
+//	var got CreateUser
+//	rec.LastRequestJSON(t, &got)
+type Recorder struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// NewRecorder returns an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Middleware records the requests passing through it. Register it last to
+// see them as they reach the transport.
+func (r *Recorder) Middleware() muxet.Middleware {
+	return func(next muxet.HTTPDoer) muxet.HTTPDoer {
+		return muxet.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := readBody(req)
+			if err != nil {
+				return nil, err
+			}
+			r.mu.Lock()
+			r.requests = append(r.requests, RecordedRequest{
+				Method: req.Method,
+				URL:    req.URL.String(),
+				Header: req.Header.Clone(),
+				Body:   body,
+			})
+			r.mu.Unlock()
+			return next.Do(req)
+		})
+	}
+}
+
+// Requests returns the recorded requests in the order they were sent
+func (r *Recorder) Requests() []RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.requests)
+}
+
+// LastRequest returns the request sent last, if any
+func (r *Recorder) LastRequest() (RecordedRequest, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) == 0 {
+		return RecordedRequest{}, false
+	}
+	return r.requests[len(r.requests)-1], true
+}
+
+// Reset forgets the recorded requests
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = nil
+}
+
+// AssertCalled fails t unless a request with the method and URL was sent;
+// the URL is matched like a Stub's
+func (r *Recorder) AssertCalled(t TB, method, url string) bool {
+	t.Helper()
+	if r.count(method, url) == 0 {
+		t.Errorf("muxettest: expected a request %s %s, sent:%s", method, url, r.summary())
+		return false
+	}
+	return true
+}
+
+// AssertNotCalled fails t if a request with the method and URL was sent
+func (r *Recorder) AssertNotCalled(t TB, method, url string) bool {
+	t.Helper()
+	if n := r.count(method, url); n > 0 {
+		t.Errorf("muxettest: expected no request %s %s, sent %d", method, url, n)
+		return false
+	}
+	return true
+}
+
+// AssertCount fails t unless exactly n requests were sent
+func (r *Recorder) AssertCount(t TB, n int) bool {
+	t.Helper()
+	if got := len(r.Requests()); got != n {
+		t.Errorf("muxettest: expected %d requests, sent %d:%s", n, got, r.summary())
+		return false
+	}
+	return true
+}
+
+// LastRequestJSON decodes the JSON body of the request sent last into v,
+// failing t if there is none or it can't be decoded
+func (r *Recorder) LastRequestJSON(t TB, v any) bool {
+	t.Helper()
+	req, ok := r.LastRequest()
+	if !ok {
+		t.Errorf("muxettest: no request was sent")
+		return false
+	}
+	if err := json.Unmarshal(req.Body, v); err != nil {
+		t.Errorf("muxettest: failed to decode the body of %s %s: %v", req.Method, req.URL, err)
+		return false
+	}
+	return true
+}
+
+func (r *Recorder) count(method, pattern string) int {
+	n := 0
+	for _, req := range r.Requests() {
+		u, err := url.Parse(req.URL)
+		if err == nil && strings.EqualFold(req.Method, method) && matchURL(pattern, u) {
+			n++
+		}
+	}
+	return n
+}
+
+// summary lists the recorded requests for failure messages
+func (r *Recorder) summary() string {
+	var b strings.Builder
+	for _, req := range r.Requests() {
+		b.WriteString("\n  " + req.Method + " " + req.URL)
+	}
+	if b.Len() == 0 {
+		return " none"
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package muxettest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// ServerTimeout bounds requests of the clients created for test servers
+const ServerTimeout = 5 * time.Second
+
+// ServerBackoff is the base retry delay of the clients created for test servers
+const ServerBackoff = 10 * time.Millisecond
+
+// NewServerClient starts an httptest.Server running handler and returns a
+// client pointed at it, with short timeouts and retry delays. opts apply on
+// top of these defaults. Close the server at the end of the test.
+func NewServerClient(handler http.Handler, opts ...muxet.Option) (*muxet.Client, *httptest.Server) {
+	srv := httptest.NewServer(handler)
+	return serverClient(srv, opts), srv
+}
+
+// NewTLSServerClient is NewServerClient with an HTTPS server, whose
+// certificate the client trusts
+func NewTLSServerClient(handler http.Handler, opts ...muxet.Option) (*muxet.Client, *httptest.Server) {
+	srv := httptest.NewTLSServer(handler)
+	return serverClient(srv, opts), srv
+}
+
+func serverClient(srv *httptest.Server, opts []muxet.Option) *muxet.Client {
+	defaults := []muxet.Option{
+		muxet.WithBaseURL(srv.URL),
+		muxet.WithHTTPClient(srv.Client()),
+		muxet.ClientOption(func(c *muxet.Client) {
+			c.SetTimeout(ServerTimeout).SetBackoff(ServerBackoff)
+		}),
+	}
+	return muxet.NewClient(append(defaults, opts...)...)
+}
@@ -0,0 +1,303 @@
+package muxettest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"unicode/utf8"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// Mode is how a cassette treats requests
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette only
+	ModeReplay Mode = iota
+	// ModeRecord sends every request and records it, replacing the cassette
+	ModeRecord
+	// ModeRecordMissing replays the recorded requests and records the others
+	ModeRecordMissing
+)
+
+// Interaction is a recorded request with its response, or the error it failed with
+type Interaction struct {
+	Request  RecordedRequest   `json:"request"`
+	Response *RecordedResponse `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// RecordedRequest is the request of an interaction
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an interaction
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Body is a recorded body, saved as text or base64 if it is binary
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	*b = raw
+	return err
+}
+
+// Matcher reports whether a recorded interaction answers a request. The
+// request has been through the scrubbers, like the recorded one.
+type Matcher func(req, recorded *RecordedRequest) bool
+
+// DefaultMatcher matches the method, URL and body, JSON compared semantically
+func DefaultMatcher(req, recorded *RecordedRequest) bool {
+	return MatchMethodAndURL(req, recorded) && matchBody(recorded.Body, req.Body)
+}
+
+// MatchMethodAndURL matches the method and URL, ignoring bodies
+func MatchMethodAndURL(req, recorded *RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL == recorded.URL
+}
+
+// Scrubber removes secrets from an interaction before it is saved. Requests
+// go through it before being matched too, so scrubbed parts still match.
+type Scrubber func(*Interaction)
+
+// ScrubHeaders masks the values of the headers in requests and responses
+func ScrubHeaders(names ...string) Scrubber {
+	return func(i *Interaction) {
+		scrubHeader(i.Request.Header, names)
+		if i.Response != nil {
+			scrubHeader(i.Response.Header, names)
+		}
+	}
+}
+
+// ScrubPattern replaces the matches of re in URLs and bodies with repl
+func ScrubPattern(re *regexp.Regexp, repl string) Scrubber {
+	return func(i *Interaction) {
+		i.Request.URL = re.ReplaceAllLiteralString(i.Request.URL, repl)
+		i.Request.Body = re.ReplaceAllLiteral(i.Request.Body, []byte(repl))
+		if i.Response != nil {
+			i.Response.Body = re.ReplaceAllLiteral(i.Response.Body, []byte(repl))
+		}
+	}
+}
+
+func scrubHeader(h http.Header, names []string) {
+	for _, name := range names {
+		if vs := h.Values(name); len(vs) > 0 {
+			h[http.CanonicalHeaderKey(name)] = slices.Repeat([]string{"[REDACTED]"}, len(vs))
+		}
+	}
+}
+
+// Cassette records requests with their responses to a file and replays them,
+// for hermetic integration tests. Register its middleware on the client and
+// save it at the end of the test:
+//
+//	cassette, err := muxettest.NewCassette("testdata/users.json", muxettest.ModeReplay)
+//	client.Use(cassette.Middleware())
+//	defer cassette.Save()
+type Cassette struct {
+	// Path is the file the cassette is loaded from and saved to
+	Path string
+	Mode Mode
+	// Match picks the interaction answering a request, DefaultMatcher if nil
+	Match Matcher
+	// Scrubbers run on every interaction, by default masking credentials
+	// and cookies
+	Scrubbers []Scrubber
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	changed      bool
+}
+
+// NewCassette loads the cassette at path. It may only be missing in the
+// recording modes.
+func NewCassette(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{
+		Path:      path,
+		Mode:      mode,
+		Scrubbers: []Scrubber{ScrubHeaders("Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie")},
+	}
+	if mode == ModeRecord {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && mode == ModeRecordMissing {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// Middleware replays or records the requests passing through it
+func (c *Cassette) Middleware() muxet.Middleware {
+	return func(next muxet.HTTPDoer) muxet.HTTPDoer {
+		return muxet.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := readBody(req)
+			if err != nil {
+				return nil, err
+			}
+			live := &Interaction{Request: RecordedRequest{
+				Method: req.Method,
+				URL:    req.URL.String(),
+				Header: req.Header.Clone(),
+				Body:   body,
+			}}
+			c.scrub(live)
+
+			if c.Mode != ModeRecord {
+				if i := c.take(&live.Request); i != nil {
+					return replay(req, i)
+				}
+				if c.Mode == ModeReplay {
+					return nil, fmt.Errorf("%w in cassette %s: %s %s", ErrNoMatch, c.Path, req.Method, live.Request.URL)
+				}
+			}
+			return c.record(next, req, body)
+		})
+	}
+}
+
+// take returns the first unused interaction matching req and marks it used
+func (c *Cassette) take(req *RecordedRequest) *Interaction {
+	match := c.Match
+	if match == nil {
+		match = DefaultMatcher
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, i := range c.interactions {
+		if !c.used[n] && match(req, &i.Request) {
+			c.used[n] = true
+			return i
+		}
+	}
+	return nil
+}
+
+// record sends req and adds the interaction to the cassette
+func (c *Cassette) record(next muxet.HTTPDoer, req *http.Request, body []byte) (*http.Response, error) {
+	i := &Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	}}
+	resp, err := next.Do(req)
+	if err == nil {
+		var respBody []byte
+		respBody, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		i.Response = &RecordedResponse{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: respBody}
+	}
+	if err != nil {
+		i.Response = nil
+		i.Error = err.Error()
+	}
+	c.scrub(i)
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, i)
+	c.used = append(c.used, true)
+	c.changed = true
+	c.mu.Unlock()
+	return resp, err
+}
+
+func (c *Cassette) scrub(i *Interaction) {
+	for _, s := range c.Scrubbers {
+		s(i)
+	}
+}
+
+// replay builds the response of a recorded interaction
+func replay(req *http.Request, i *Interaction) (*http.Response, error) {
+	if i.Response == nil {
+		return nil, errors.New(i.Error)
+	}
+	resp := Response(req, i.Response.Status, i.Response.Header, "")
+	resp.Body = io.NopCloser(bytes.NewReader(i.Response.Body))
+	resp.ContentLength = int64(len(i.Response.Body))
+	return resp, nil
+}
+
+// Save writes the cassette to its file if something was recorded
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if dir := filepath.Dir(c.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to write cassette: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	c.changed = false
+	return nil
+}
+
+// Interactions returns the interactions of the cassette
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Interaction, len(c.interactions))
+	for n, i := range c.interactions {
+		out[n] = *i
+	}
+	return out
+}
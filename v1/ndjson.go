@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamJSON reads a newline-delimited JSON (NDJSON, JSON Lines) response from
+// url incrementally and calls fn with each record as it arrives. Blank lines
+// are skipped. An error from fn stops reading and is returned as is.
+func (c *Client) StreamJSON(ctx context.Context, url string, fn func(raw json.RawMessage) error, opts ...RequestOption) error {
+	opts = append([]RequestOption{WithHeader("Accept", "application/x-ndjson, application/jsonl, application/json")}, opts...)
+	resp, err := c.DoStream(ctx, http.MethodGet, url, nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Stream.Close()
+
+	r := bufio.NewReader(resp.Stream)
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read line %d: %w", line, err)
+		}
+		if record := bytes.TrimSpace(b); len(record) > 0 {
+			if !json.Valid(record) {
+				return fmt.Errorf("invalid JSON on line %d", line)
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
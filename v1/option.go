@@ -0,0 +1,70 @@
+package v1
+
+import "net/http"
+
+// Option configures a Client in NewClient. Every RequestOption is an Option
+// as well and then applies to all requests of the client, e.g.
+// NewClient(WithBaseURL(url), WithTimeout(10*time.Second), WithHeader("X-Tenant", "acme")).
+type Option interface {
+	apply(c *Client)
+}
+
+// ClientOption configures a setting of the client itself
+type ClientOption func(*Client)
+
+func (o ClientOption) apply(c *Client) { o(c) }
+
+// apply makes o a default of every request of c
+func (o RequestOption) apply(c *Client) {
+	c.defaultOpts = append(c.defaultOpts, o)
+}
+
+// WithBaseURL sets the base URL that relative request URLs are resolved against
+func WithBaseURL(base string) ClientOption {
+	return func(c *Client) { c.SetBaseURL(base) }
+}
+
+// WithHeaders sets headers sent with every request
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.SetHeader(k, v)
+		}
+	}
+}
+
+// WithRetry retries failed requests up to maxRetries times, waiting according
+// to backoff in between; a nil backoff keeps the default exponential backoff
+func WithRetry(maxRetries int, backoff Backoff) ClientOption {
+	return func(c *Client) {
+		c.SetMaxRetries(maxRetries)
+		if backoff != nil {
+			c.SetBackoffStrategy(backoff)
+		}
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTPDoer
+func WithHTTPClient(d HTTPDoer) ClientOption {
+	return func(c *Client) { c.SetHTTPClient(d) }
+}
+
+// WithTransport sets the RoundTripper of the underlying *http.Client
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) { c.SetTransport(rt) }
+}
+
+// WithCookieJar sets the cookie jar of the underlying *http.Client
+func WithCookieJar(jar http.CookieJar) ClientOption {
+	return func(c *Client) { c.SetCookieJar(jar) }
+}
+
+// WithUnixSocket sends every request to the Unix domain socket at path
+func WithUnixSocket(path string) ClientOption {
+	return func(c *Client) { c.SetUnixSocket(path) }
+}
+
+// WithMiddleware appends middleware to the transport chain
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) { c.Use(mw...) }
+}
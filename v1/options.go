@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOption customizes a single request
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	idempotent bool
+	headers    map[string]string
+	cookies    []*http.Cookie
+	query      url.Values
+	queryValue any
+	pathParams map[string]string
+	uriVars    map[string]any
+	stream     bool
+	gzip       bool
+	// raw is the prepared request passed to Client.Do
+	raw *http.Request
+
+	// timeout and overrides replace client settings for this request
+	timeout   time.Duration
+	overrides []func(*Client)
+
+	// beforeHooks and afterHooks run after the client's hooks
+	beforeHooks []func(*Request) error
+	afterHooks  []func(*Response) error
+
+	downloadProgress func(Progress)
+	uploadProgress   func(sent, total int64)
+	resume           bool
+
+	checksumAlgorithm   string
+	checksum            string
+	checksumFromHeaders bool
+
+	// cache tunes the ResponseCache for this request
+	cache cacheOptions
+
+	// proxy replaces the proxy of the client, proxyErr reports an invalid one
+	proxy    *proxyChoice
+	proxyErr error
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithIdempotent marks a request as safe to retry regardless of its method
+func WithIdempotent() RequestOption {
+	return func(o *requestOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithHeader sets a header on a single request
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[key] = value
+	}
+}
+
+// WithCookie sends a cookie with a single request, next to those of the
+// Cookie header and the cookie jar. Only its name and value are sent.
+func WithCookie(cookie *http.Cookie) RequestOption {
+	return func(o *requestOptions) {
+		o.cookies = append(o.cookies, cookie)
+	}
+}
+
+// addCookies appends cookies to the Cookie header in headers
+func addCookies(headers map[string]string, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+	key, value := "Cookie", ""
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == "Cookie" {
+			key, value = k, v
+		}
+	}
+	for _, c := range cookies {
+		pair := (&http.Cookie{Name: c.Name, Value: c.Value, Quoted: c.Quoted}).String()
+		if value != "" {
+			value += "; "
+		}
+		value += pair
+	}
+	headers[key] = value
+}
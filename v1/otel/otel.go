@@ -0,0 +1,162 @@
+// Package otel traces muxet clients with OpenTelemetry. Each attempt becomes
+// a client span with the HTTP semantic convention attributes, retries are
+// marked with their resend count, and the trace context is propagated to the
+// server:
+//
+//	otel.Instrument(client)
+package otel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// ScopeName is the instrumentation scope of the tracer
+const ScopeName = "github.com/Wizz-Tech/muxet/v1/otel"
+
+type config struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer instead of the global one
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.provider = tp }
+}
+
+// WithPropagators sets the propagators injecting the trace context instead
+// of the global ones
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) { c.propagators = p }
+}
+
+// Instrument adds the tracing middleware to c
+func Instrument(c *muxet.Client, opts ...Option) *muxet.Client {
+	return c.Use(Middleware(opts...))
+}
+
+// Middleware creates a span for every attempt passing through it. Register
+// it first so it also covers the other middleware.
+func Middleware(opts ...Option) muxet.Middleware {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.provider == nil {
+		cfg.provider = otel.GetTracerProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+	tracer := cfg.provider.Tracer(ScopeName)
+
+	return func(next muxet.HTTPDoer) muxet.HTTPDoer {
+		return muxet.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), req.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(req)...))
+
+			req = req.WithContext(ctx)
+			req.Header = req.Header.Clone()
+			cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.Do(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.SetAttributes(attribute.String("error.type", errorType(err)))
+				span.End()
+				return resp, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= 400 {
+				span.SetStatus(codes.Error, "")
+				span.SetAttributes(attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+			}
+			if resp.StatusCode == http.StatusSwitchingProtocols || resp.Body == nil {
+				span.End()
+				return resp, nil
+			}
+			// the attempt lasts until the body is read
+			resp.Body = &spanBody{ReadCloser: resp.Body, span: span}
+			return resp, nil
+		})
+	}
+}
+
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	u := *req.URL
+	u.User = nil
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", u.String()),
+		attribute.String("server.address", req.URL.Hostname()),
+	}
+	port := req.URL.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[req.URL.Scheme]
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, attribute.Int("server.port", p))
+	}
+	if n := muxet.AttemptFromContext(req.Context()); n > 1 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", n-1))
+	}
+	return attrs
+}
+
+// errorType classifies transport errors for the error.type attribute
+func errorType(err error) string {
+	if muxet.IsTimeout(err) {
+		return "timeout"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return "network"
+	}
+	return "_OTHER"
+}
+
+// spanBody ends the span of an attempt once its body is read or closed
+type spanBody struct {
+	io.ReadCloser
+	span  trace.Span
+	ended bool
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.span.RecordError(err)
+	}
+	if err != nil {
+		b.end()
+	}
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	b.end()
+	return b.ReadCloser.Close()
+}
+
+func (b *spanBody) end() {
+	if !b.ended {
+		b.ended = true
+		b.span.End()
+	}
+}
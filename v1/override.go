@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+// WithTimeout bounds a single request, including retries, by d instead of the
+// client timeout. It applies on top of the deadline of the request context.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+		o.overrides = append(o.overrides, func(c *Client) { c.timeout = d })
+	}
+}
+
+// WithRetries sets the maximum number of retries of a single request; 0 disables them
+func WithRetries(n int) RequestOption {
+	return withOverride(func(c *Client) { c.maxRetries = n })
+}
+
+// WithBackoff sets the strategy computing delays between retries of a single request
+func WithBackoff(b Backoff) RequestOption {
+	return withOverride(func(c *Client) { c.backoff = b })
+}
+
+// WithLogger logs a single request to l instead of the client logger; nil silences it
+func WithLogger(l Logger) RequestOption {
+	return withOverride(func(c *Client) { c.logger = l })
+}
+
+// WithBeforeRequestHook replaces all before request hooks of the client for a single request; nil disables them
+func WithBeforeRequestHook(fn func(*Request) error) RequestOption {
+	return withOverride(func(c *Client) { c.BeforeRequest, c.beforeHooks = fn, nil })
+}
+
+// WithAfterResponseHook replaces all after response hooks of the client for a single request; nil disables them
+func WithAfterResponseHook(fn func(*Response) error) RequestOption {
+	return withOverride(func(c *Client) { c.AfterResponse, c.afterHooks = fn, nil })
+}
+
+// WithOnRetryHook replaces the client's OnRetry hook for a single request; nil disables it
+func WithOnRetryHook(fn func(attempt int, req *Request, resp *Response, err error)) RequestOption {
+	return withOverride(func(c *Client) { c.OnRetry = fn })
+}
+
+// WithOnErrorHook replaces the client's OnError hook for a single request; nil disables it
+func WithOnErrorHook(fn func(req *Request, resp *Response, err error)) RequestOption {
+	return withOverride(func(c *Client) { c.OnError = fn })
+}
+
+// WithOnRedirectHook replaces the client's OnRedirect hook for a single request; nil disables it
+func WithOnRedirectHook(fn func(req *http.Request, via []*http.Request) error) RequestOption {
+	return withOverride(func(c *Client) { c.OnRedirect = fn })
+}
+
+func withOverride(fn func(*Client)) RequestOption {
+	return func(o *requestOptions) {
+		o.overrides = append(o.overrides, fn)
+	}
+}
+
+// snapshot returns a copy of the client configuration for a single request
+// and the options of the request: the client's default options followed by
+// opts. Their overrides are applied to the copy, and setters called while the
+// request runs don't affect it. The copy shares breakers, limiters and other
+// state with c.
+func (c *Client) snapshot(opts []RequestOption) (*Client, *requestOptions) {
+	c.mu.RLock()
+	cp := *c
+	c.mu.RUnlock()
+	ro := newRequestOptions(append(slices.Clip(cp.defaultOpts), opts...))
+	for _, fn := range ro.overrides {
+		fn(&cp)
+	}
+	return &cp, ro
+}
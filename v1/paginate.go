@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Paginate fetches url and follows RFC 5988 `Link: <...>; rel="next"` headers,
+// yielding every page in turn. Iteration stops after the last page, on the
+// first error, or when the caller breaks out of the loop. A next link to a page
+// already fetched is an error, so a looping server can't paginate forever.
+func (c *Client) Paginate(ctx context.Context, url string, opts ...RequestOption) iter.Seq2[*Response, error] {
+	return c.paginate(ctx, url, LinkNext, opts)
+}
+
+func (c *Client) paginate(ctx context.Context, url string, next NextPageFunc, opts []RequestOption) iter.Seq2[*Response, error] {
+	return func(yield func(*Response, error) bool) {
+		visited := make(map[string]bool)
+		for url != "" {
+			if visited[url] {
+				yield(nil, fmt.Errorf("pagination loop: %s was already fetched", url))
+				return
+			}
+			visited[url] = true
+			_, page, err := c.do(ctx, http.MethodGet, url, nil, nil, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			// next links are absolute, as is the URL the page was fetched from
+			if page.Raw != nil && page.Raw.Request != nil {
+				visited[page.Raw.Request.URL.String()] = true
+			}
+			if !yield(page, nil) {
+				return
+			}
+			nextURL, done := next(page)
+			if done {
+				return
+			}
+			url = nextURL
+		}
+	}
+}
+
+// nextLink returns the absolute URL of the rel="next" link of page, if any
+func nextLink(page *Response) string {
+	links := parseLinks(http.Header(page.Headers).Values("Link"))
+	next, ok := links["next"]
+	if !ok {
+		return ""
+	}
+	if page.Raw != nil && page.Raw.Request != nil {
+		if ref, err := url.Parse(next); err == nil {
+			return page.Raw.Request.URL.ResolveReference(ref).String()
+		}
+	}
+	return next
+}
+
+// parseLinks parses RFC 5988 Link header values into a map of rel to target URL
+func parseLinks(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, v := range values {
+		for _, link := range splitLinks(v) {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+			for _, param := range parts[1:] {
+				key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if _, seen := links[strings.ToLower(rel)]; !seen {
+						links[strings.ToLower(rel)] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// splitLinks splits a Link header value on the commas separating links,
+// ignoring commas inside <...> targets and quoted parameters
+func splitLinks(v string) []string {
+	var links []string
+	var inURL, inQuote bool
+	start := 0
+	for i, r := range v {
+		switch {
+		case r == '<' && !inQuote:
+			inURL = true
+		case r == '>' && !inQuote:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuote = !inQuote
+		case r == ',' && !inURL && !inQuote:
+			links = append(links, v[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, v[start:])
+}
+
+// NextPageFunc returns the URL of the page following resp, or done once resp is the last page
+type NextPageFunc func(resp *Response) (nextURL string, done bool)
+
+// LinkNext is a NextPageFunc following RFC 5988 rel="next" links
+func LinkNext(resp *Response) (string, bool) {
+	next := nextLink(resp)
+	return next, next == ""
+}
+
+// Pager walks a paginated collection of T
+type Pager[T any] struct {
+	// Next locates the following page; required
+	Next NextPageFunc
+	// Items extracts the items of a page; by default the body is decoded as a JSON array
+	Items func(resp *Response) ([]T, error)
+}
+
+// Pages fetches url and the pages after it, yielding the items of each page.
+// Every page goes through the client, so rate limits and retries apply per page.
+func (p Pager[T]) Pages(ctx context.Context, c *Client, url string, opts ...RequestOption) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		for page, err := range c.paginate(ctx, url, p.Next, opts) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			items, err := p.items(page)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to decode page: %w", err))
+				return
+			}
+			if !yield(items, nil) {
+				return
+			}
+		}
+	}
+}
+
+// All collects the items of every page
+func (p Pager[T]) All(ctx context.Context, c *Client, url string, opts ...RequestOption) ([]T, error) {
+	var all []T
+	for items, err := range p.Pages(ctx, c, url, opts...) {
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+func (p Pager[T]) items(page *Response) ([]T, error) {
+	if p.Items != nil {
+		return p.Items(page)
+	}
+	var items []T
+	err := page.JSON(&items)
+	return items, err
+}
@@ -0,0 +1,45 @@
+package v1
+
+import "strings"
+
+// WithPathParam sets the value of a {name} placeholder in the URL of a single
+// request, overriding the client's value (see SetPathParam)
+func WithPathParam(name, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.pathParams == nil {
+			o.pathParams = make(map[string]string)
+		}
+		o.pathParams[name] = value
+	}
+}
+
+// WithPathParams sets several path parameters of a single request
+func WithPathParams(params map[string]string) RequestOption {
+	return func(o *requestOptions) {
+		if o.pathParams == nil {
+			o.pathParams = make(map[string]string)
+		}
+		for k, v := range params {
+			o.pathParams[k] = v
+		}
+	}
+}
+
+// expandPathParams expands rawURL as a URI template with the variables of
+// the request, its path parameters and then those of the client
+func expandPathParams(rawURL string, client, request map[string]string, uriVars map[string]any) (string, error) {
+	if !strings.Contains(rawURL, "{") {
+		return rawURL, nil
+	}
+	vars := make(map[string]any, len(client)+len(request)+len(uriVars))
+	for k, v := range client {
+		vars[k] = v
+	}
+	for k, v := range request {
+		vars[k] = v
+	}
+	for k, v := range uriVars {
+		vars[k] = v
+	}
+	return expandTemplate(rawURL, vars, true)
+}
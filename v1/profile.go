@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile is the configuration of a client for one upstream, as loaded from
+// a config file. String values of Auth and Headers may reference environment
+// variables as $VAR or ${VAR}, so secrets don't have to live in the file.
+type Profile struct {
+	BaseURL string            `json:"base_url"`
+	Headers map[string]string `json:"headers"`
+	Auth    *ProfileAuth      `json:"auth"`
+	Timeout Duration          `json:"timeout"`
+	// AttemptTimeout bounds each attempt of a request, see Client.SetAttemptTimeout
+	AttemptTimeout Duration      `json:"attempt_timeout"`
+	Retry          *ProfileRetry `json:"retry"`
+}
+
+// ProfileAuth authenticates requests with a bearer token or, when Username is
+// set, with basic auth
+type ProfileAuth struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ProfileRetry is the retry policy of a profile. Backoff is the base delay of
+// the exponential backoff, capped at MaxBackoff when set.
+type ProfileRetry struct {
+	MaxRetries     int      `json:"max_retries"`
+	Backoff        Duration `json:"backoff"`
+	MaxBackoff     Duration `json:"max_backoff"`
+	MaxElapsedTime Duration `json:"max_elapsed_time"`
+}
+
+// Duration is a time.Duration read from a string like "1.5s" or from a
+// number of nanoseconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Profiles maps profile names to their configuration:
+//
+//	{
+//	  "payments": {
+//	    "base_url": "https://payments.internal/v1",
+//	    "auth": {"token": "${PAYMENTS_TOKEN}"},
+//	    "timeout": "3s",
+//	    "retry": {"max_retries": 2, "backoff": "200ms"}
+//	  }
+//	}
+type Profiles map[string]*Profile
+
+// profileFormats maps file extensions to the decoder of their profiles
+var (
+	profileFormatsMu sync.RWMutex
+	profileFormats   = map[string]Decoder{".json": json.Unmarshal}
+)
+
+// RegisterProfileFormat makes LoadProfiles decode files with extension ext,
+// like ".toml", with unmarshal. Importing codec/yaml registers .yaml and .yml.
+func RegisterProfileFormat(ext string, unmarshal Decoder) {
+	profileFormatsMu.Lock()
+	defer profileFormatsMu.Unlock()
+	profileFormats[strings.ToLower(ext)] = unmarshal
+}
+
+// LoadProfiles reads profiles from a file, decoded by its extension: JSON,
+// and YAML once codec/yaml is imported, see RegisterProfileFormat. Files
+// with other extensions are read as JSON.
+func LoadProfiles(path string) (Profiles, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	profileFormatsMu.RLock()
+	unmarshal, ok := profileFormats[ext]
+	profileFormatsMu.RUnlock()
+	if !ok {
+		switch ext {
+		case ".yaml", ".yml":
+			return nil, fmt.Errorf("failed to load profiles from %s: import package codec/yaml to read YAML files", path)
+		}
+		unmarshal = json.Unmarshal
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	profiles, err := ParseProfiles(data, unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles from %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// ParseProfiles decodes profiles with unmarshal, e.g. json.Unmarshal
+func ParseProfiles(data []byte, unmarshal Decoder) (Profiles, error) {
+	var profiles Profiles
+	if err := unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// ProfileClient returns a new client configured by the named profile
+func (p Profiles) ProfileClient(name string) (*Client, error) {
+	profile, ok := p[name]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	return profile.NewClient(), nil
+}
+
+// NewClient returns a new client configured by p
+func (p *Profile) NewClient() *Client {
+	c := NewClient()
+	p.apply(c)
+	return c
+}
+
+// apply configures c with the settings of p
+func (p *Profile) apply(c *Client) {
+	if p.BaseURL != "" {
+		c.SetBaseURL(p.BaseURL)
+	}
+	for k, v := range p.Headers {
+		c.SetHeader(k, os.ExpandEnv(v))
+	}
+	if p.Auth != nil {
+		if auth := p.Auth.header(); auth != "" {
+			c.SetHeader("Authorization", auth)
+		}
+	}
+	if p.Timeout > 0 {
+		c.SetTimeout(time.Duration(p.Timeout))
+	}
+	if p.AttemptTimeout > 0 {
+		c.SetAttemptTimeout(time.Duration(p.AttemptTimeout))
+	}
+	if r := p.Retry; r != nil {
+		c.SetMaxRetries(r.MaxRetries)
+		if r.Backoff > 0 {
+			c.SetBackoffStrategy(ExponentialBackoff{Base: time.Duration(r.Backoff), Max: time.Duration(r.MaxBackoff)})
+		}
+		if r.MaxElapsedTime > 0 {
+			c.SetMaxElapsedTime(time.Duration(r.MaxElapsedTime))
+		}
+	}
+}
+
+// header returns the Authorization header value, or "" without credentials
+func (a *ProfileAuth) header() string {
+	if a.Username != "" {
+		creds := os.ExpandEnv(a.Username) + ":" + os.ExpandEnv(a.Password)
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+	}
+	if token := os.ExpandEnv(a.Token); token != "" {
+		return "Bearer " + token
+	}
+	return ""
+}
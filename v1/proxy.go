@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ProxyProvider picks the proxy of each attempt, e.g. to rotate through a
+// pool for scraping or to egress from several regions
+type ProxyProvider interface {
+	// Proxy returns the proxy to send req through, or nil to connect directly
+	Proxy(req *http.Request) (*url.URL, error)
+	// Failed is called when an attempt through proxy failed with a transport
+	// error
+	Failed(proxy *url.URL, err error)
+}
+
+// ProxyRotation is how a ProxyPool moves through its proxies
+type ProxyRotation int
+
+const (
+	// ProxyRoundRobin uses the proxies in turn, one per attempt
+	ProxyRoundRobin ProxyRotation = iota
+	// ProxyOnFailure keeps using a proxy until an attempt through it fails,
+	// then moves to the next one
+	ProxyOnFailure
+)
+
+// ProxyPool is a ProxyProvider rotating through a fixed list of proxies
+type ProxyPool struct {
+	rotation ProxyRotation
+
+	mu      sync.Mutex
+	proxies []*url.URL
+	next    int
+}
+
+// NewProxyPool returns a pool of the proxies at proxyURLs, which take the
+// forms accepted by SetProxy
+func NewProxyPool(rotation ProxyRotation, proxyURLs ...string) (*ProxyPool, error) {
+	if len(proxyURLs) == 0 {
+		return nil, errors.New("proxy pool is empty")
+	}
+	p := &ProxyPool{rotation: rotation, proxies: make([]*url.URL, 0, len(proxyURLs))}
+	for _, s := range proxyURLs {
+		u, err := parseProxyURL(s)
+		if err != nil {
+			return nil, err
+		}
+		p.proxies = append(p.proxies, u)
+	}
+	return p, nil
+}
+
+func (p *ProxyPool) Proxy(*http.Request) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	u := p.proxies[p.next]
+	if p.rotation == ProxyRoundRobin {
+		p.next = (p.next + 1) % len(p.proxies)
+	}
+	return u, nil
+}
+
+func (p *ProxyPool) Failed(proxy *url.URL, _ error) {
+	if p.rotation != ProxyOnFailure {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// concurrent failures through the same proxy move on only once
+	if p.proxies[p.next] == proxy {
+		p.next = (p.next + 1) % len(p.proxies)
+	}
+}
+
+// SetProxyProvider picks the proxy of each attempt with p instead of the
+// client's proxy; nil stops using a provider. WithProxy still overrides it.
+func (c *Client) SetProxyProvider(p ProxyProvider) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.proxyProvider = p
+	return c
+}
+
+// WithProxy routes a request through the proxy at proxyURL, see SetProxy; an
+// empty proxyURL connects directly. Given to NewClient, it applies to every
+// request.
+func WithProxy(proxyURL string) RequestOption {
+	choice := &proxyChoice{}
+	var err error
+	if proxyURL != "" {
+		choice.url, err = parseProxyURL(proxyURL)
+	}
+	return func(o *requestOptions) {
+		o.proxy, o.proxyErr = choice, err
+	}
+}
+
+type proxyKey struct{}
+
+// proxyChoice is the proxy of an attempt; a nil url connects directly
+type proxyChoice struct {
+	url *url.URL
+	// provided is set when the choice comes from the ProxyProvider
+	provided bool
+}
+
+// chooseProxy returns req carrying the proxy of the attempt, if the request
+// or a ProxyProvider picks one
+func (c *Client) chooseProxy(muxReq *Request, req *http.Request) (*http.Request, error) {
+	choice := muxReq.opts.proxy
+	if muxReq.opts.proxyErr != nil {
+		return nil, muxReq.opts.proxyErr
+	}
+	if choice == nil && c.proxyProvider != nil {
+		u, err := c.proxyProvider.Proxy(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pick proxy: %w", err)
+		}
+		choice = &proxyChoice{url: u, provided: true}
+	}
+	if choice == nil {
+		return req, nil
+	}
+	return req.WithContext(context.WithValue(req.Context(), proxyKey{}, choice)), nil
+}
+
+// proxyFailed reports a transport error to the ProxyProvider that picked the
+// proxy of req
+func (c *Client) proxyFailed(req *http.Request, err error) {
+	choice, ok := req.Context().Value(proxyKey{}).(*proxyChoice)
+	if ok && choice.provided && choice.url != nil && c.proxyProvider != nil {
+		c.proxyProvider.Failed(choice.url, err)
+	}
+}
+
+// requestProxy returns a Transport.Proxy function using the proxy picked for
+// the request, or fallback; a nil fallback connects directly
+func requestProxy(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if choice, ok := req.Context().Value(proxyKey{}).(*proxyChoice); ok {
+			return choice.url, nil
+		}
+		if fallback == nil {
+			return nil, nil
+		}
+		return fallback(req)
+	}
+}
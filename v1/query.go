@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// WithQueryParam sets a query parameter on a single request, replacing any
+// value of key from the client defaults or the URL
+func WithQueryParam(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		o.query.Set(key, value)
+	}
+}
+
+// WithQueryParams sets query parameters on a single request; keys with
+// several values are sent as repeated parameters
+func WithQueryParams(params url.Values) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		for k, v := range params {
+			o.query[k] = append([]string(nil), v...)
+		}
+	}
+}
+
+// WithQuery encodes the fields of a struct as query parameters of a single
+// request, named by their `query` tags, or `url` tags if there are none. They
+// replace client defaults and the URL's own query; WithQueryParam wins over them.
+//
+//	type ListOptions struct {
+//		Page  int       `query:"page,omitempty"`
+//		IDs   []int     `query:"ids,comma"`
+//		Since time.Time `query:"since,unix"`
+//	}
+func WithQuery(v any) RequestOption {
+	return func(o *requestOptions) {
+		o.queryValue = v
+	}
+}
+
+// requestURL expands rawURL as a URI template, resolves it against the
+// base URL and merges the query parameters into it: client defaults, then the
+// URL's own query, then the parameters of the request, each replacing the
+// keys of the former
+func (c *Client) requestURL(rawURL string, ro *requestOptions) (string, error) {
+	rawURL, err := expandPathParams(rawURL, c.pathParams, ro.pathParams, ro.uriVars)
+	if err != nil {
+		return "", err
+	}
+	fullURL, err := c.resolveURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if len(c.queryParams) == 0 && len(ro.query) == 0 && ro.queryValue == nil {
+		return fullURL, nil
+	}
+
+	var encoded url.Values
+	if ro.queryValue != nil {
+		tag := "query"
+		if !hasTag(ro.queryValue, tag) && hasTag(ro.queryValue, "url") {
+			tag = "url"
+		}
+		if encoded, err = encodeValues(ro.queryValue, tag); err != nil {
+			return "", fmt.Errorf("failed to encode query: %w", err)
+		}
+	}
+
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid request URL: %w", err)
+	}
+	q := make(url.Values)
+	for _, layer := range []url.Values{c.queryParams, u.Query(), encoded, ro.query} {
+		for k, v := range layer {
+			q[k] = v
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
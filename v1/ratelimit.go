@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned in non-blocking mode when the client-side rate limit is exhausted
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimiter is a token bucket refilled at a fixed rate up to a burst size
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rps requests per second with bursts of up to burst requests
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	b := float64(max(burst, 1))
+	return &RateLimiter{
+		rate:   rps,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// Allow takes a token if one is available right now
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a token is available or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	l.tokens--
+	var d time.Duration
+	if l.tokens < 0 {
+		if l.rate <= 0 {
+			l.tokens++
+			l.mu.Unlock()
+			return ErrRateLimited
+		}
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if err := sleep(ctx, d); err != nil {
+		// hand the reserved token back
+		l.mu.Lock()
+		l.tokens = min(l.tokens+1, l.burst)
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens accrued since the last call; callers hold l.mu
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed.Seconds()*l.rate, l.burst)
+		l.last = now
+	}
+}
+
+// rateLimit applies the client's limiter settings, optionally one bucket per host
+type rateLimit struct {
+	mu          sync.Mutex
+	rps         float64
+	burst       int
+	perHost     bool
+	nonBlocking bool
+	shared      *RateLimiter
+	limiters    map[string]*RateLimiter
+}
+
+// configure changes the limit and resets all buckets
+func (r *rateLimit) configure(rps float64, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rps, r.burst = rps, burst
+	r.shared, r.limiters = nil, nil
+}
+
+// limiter returns the bucket for host, or nil when rate limiting is disabled
+func (r *rateLimit) limiter(host string) (*RateLimiter, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rps <= 0 {
+		return nil, r.nonBlocking
+	}
+	if !r.perHost {
+		if r.shared == nil {
+			r.shared = NewRateLimiter(r.rps, r.burst)
+		}
+		return r.shared, r.nonBlocking
+	}
+	if r.limiters == nil {
+		r.limiters = make(map[string]*RateLimiter)
+	}
+	l, ok := r.limiters[host]
+	if !ok {
+		l = NewRateLimiter(r.rps, r.burst)
+		r.limiters[host] = l
+	}
+	return l, r.nonBlocking
+}
+
+// wait blocks until a request to host may be sent, or fails right away in non-blocking mode
+func (r *rateLimit) wait(ctx context.Context, host string) error {
+	l, nonBlocking := r.limiter(host)
+	if l == nil {
+		return nil
+	}
+	if nonBlocking {
+		if !l.Allow() {
+			return fmt.Errorf("%w for %s", ErrRateLimited, host)
+		}
+		return nil
+	}
+	return l.Wait(ctx)
+}
+
+// rateLimiter returns the client's rate limit settings, creating them on first use
+func (c *Client) rateLimiter() *rateLimit {
+	if c.rateLimit == nil {
+		c.rateLimit = &rateLimit{}
+	}
+	return c.rateLimit
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// redacted replaces masked values in logs
+const redacted = "[REDACTED]"
+
+// defaultMaxLoggedBody is how many bytes of a body logs show by default
+const defaultMaxLoggedBody = 4 << 10
+
+// redactedHeaders are always masked in logs
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// AddRedactedHeaders masks the values of these headers in debug dumps and
+// logged curl commands, on top of credentials and cookies
+func (c *Client) AddRedactedHeaders(names ...string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	headers := slices.Clip(c.redactHeaders)
+	for _, name := range names {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+	c.redactHeaders = headers
+	return c
+}
+
+// AddRedactedJSONFields masks fields of logged JSON bodies, given as dot
+// separated paths from the root like "user.password". A "*" segment matches
+// any field and arrays are looked through, so "items.card" masks the card of
+// every item.
+func (c *Client) AddRedactedJSONFields(paths ...string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fields := slices.Clip(c.redactFields)
+	for _, path := range paths {
+		fields = append(fields, strings.Split(path, "."))
+	}
+	c.redactFields = fields
+	return c
+}
+
+// AddRedactionPatterns masks every match of the patterns in log lines, debug
+// dumps and logged curl commands included
+func (c *Client) AddRedactionPatterns(patterns ...*regexp.Regexp) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redactPatterns = append(slices.Clip(c.redactPatterns), patterns...)
+	return c
+}
+
+// SetMaxLoggedBodySize sets how many bytes of each body debug dumps and
+// logged curl commands show; 0 hides bodies
+func (c *Client) SetMaxLoggedBodySize(n int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxLoggedBody = n
+	return c
+}
+
+// redactHeader masks the value of a redacted header
+func (c *Client) redactHeader(name, value string) string {
+	name = http.CanonicalHeaderKey(name)
+	if slices.Contains(redactedHeaders, name) || slices.Contains(c.redactHeaders, name) {
+		return redacted
+	}
+	return value
+}
+
+// redactText masks the matches of the redaction patterns
+func (c *Client) redactText(s string) string {
+	for _, re := range c.redactPatterns {
+		s = re.ReplaceAllLiteralString(s, redacted)
+	}
+	return s
+}
+
+// redactBody masks the redacted JSON fields and pattern matches of a body
+// before it is cut. JSON bodies with masked fields are compacted.
+func (c *Client) redactBody(data []byte) []byte {
+	if len(c.redactFields) > 0 && json.Valid(data) {
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if dec.Decode(&v) == nil {
+			masked := false
+			for _, path := range c.redactFields {
+				masked = redactJSON(v, path) || masked
+			}
+			if masked {
+				if out, err := json.Marshal(v); err == nil {
+					data = out
+				}
+			}
+		}
+	}
+	for _, re := range c.redactPatterns {
+		data = re.ReplaceAllLiteral(data, []byte(redacted))
+	}
+	return data
+}
+
+// redactError returns the message of err for logs, with the redaction rules
+// applied to the response body an HTTPError shows
+func (c *Client) redactError(err error) string {
+	msg := err.Error()
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Err == nil && len(httpErr.Body) > 0 {
+		msg = strings.Replace(msg, string(httpErr.Body), string(c.redactBody(httpErr.Body)), 1)
+	}
+	return msg
+}
+
+// redactJSON masks the fields at path in v and reports whether any was found
+func redactJSON(v any, path []string) bool {
+	masked := false
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			masked = redactJSON(elem, path) || masked
+		}
+	case map[string]any:
+		for k, field := range v {
+			if path[0] != "*" && path[0] != k {
+				continue
+			}
+			if len(path) == 1 {
+				v[k] = redacted
+				masked = true
+			} else {
+				masked = redactJSON(field, path[1:]) || masked
+			}
+		}
+	}
+	return masked
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// DefaultMaxRedirects is how many redirects a request follows by default,
+// like net/http
+const DefaultMaxRedirects = 10
+
+// credentialHeaders are removed from redirects to another host
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+// ErrTooManyRedirects is returned when a request is redirected more times
+// than allowed, see SetMaxRedirects
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// Redirect is a redirect followed by a request: the URL that answered with it
+// and its status
+type Redirect struct {
+	URL        string
+	StatusCode int
+}
+
+// RedirectPolicy decides whether a request follows the redirect to req; via
+// holds the requests sent so far, oldest first. Returning false stops there
+// and returns the redirect response to the caller.
+type RedirectPolicy func(req *http.Request, via []*http.Request) bool
+
+// SetMaxRedirects sets how many redirects a request follows before failing
+// with ErrTooManyRedirects. Redirects are followed by the underlying
+// *http.Client; other HTTPDoers handle them themselves.
+func (c *Client) SetMaxRedirects(n int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRedirects = n
+	return c
+}
+
+// SetFollowRedirects disables following redirects when false: 3xx responses
+// are returned to the caller as they are instead of failing
+func (c *Client) SetFollowRedirects(follow bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.followRedirects = follow
+	return c
+}
+
+// SetRedirectPolicy sets the policy deciding which redirects are followed,
+// within the limit of SetMaxRedirects; nil follows them all
+func (c *Client) SetRedirectPolicy(p RedirectPolicy) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redirectPolicy = p
+	return c
+}
+
+// SetStripCredentialsOnRedirect controls whether Authorization, Cookie and
+// the headers added with AddRedirectStripHeaders are removed when a redirect
+// leads to another host, which is on by default. Even when off, net/http
+// drops Authorization and Cookie on redirects to unrelated domains.
+func (c *Client) SetStripCredentialsOnRedirect(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keepCredentials = !enabled
+	return c
+}
+
+// AddRedirectStripHeaders removes these headers too, like custom API key
+// headers, when a redirect leads to another host
+func (c *Client) AddRedirectStripHeaders(names ...string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	headers := slices.Clip(c.stripHeaders)
+	for _, name := range names {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+	c.stripHeaders = headers
+	return c
+}
+
+// SetOnRedirectHook sets a hook called before following each redirect, with
+// the request to the new location, whose Response field is the redirect, and
+// the requests sent so far. An error aborts the request without retries.
+func (c *Client) SetOnRedirectHook(fn func(req *http.Request, via []*http.Request) error) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OnRedirect = fn
+	return c
+}
+
+// redirectHookError marks a failure of the OnRedirect hook
+type redirectHookError struct {
+	err error
+}
+
+func (e *redirectHookError) Error() string { return e.err.Error() }
+
+// withRedirects returns d following redirects as configured, if it is an
+// *http.Client
+func (c *Client) withRedirects(d HTTPDoer, unfollowed *bool) HTTPDoer {
+	hc, ok := d.(*http.Client)
+	if !ok {
+		return d
+	}
+	cp := *hc
+	next := hc.CheckRedirect
+	cp.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !c.followRedirects || c.redirectPolicy != nil && !c.redirectPolicy(req, via) {
+			*unfollowed = true
+			return http.ErrUseLastResponse
+		}
+		if len(via) > c.maxRedirects {
+			return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, c.maxRedirects)
+		}
+		// the headers of every hop are copied from the first request
+		if !c.keepCredentials && !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			for _, name := range slices.Concat(credentialHeaders, c.stripHeaders) {
+				req.Header.Del(name)
+			}
+		}
+		if err := c.onRedirect(req, via); err != nil {
+			return &redirectHookError{err}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+	return &cp
+}
+
+// redirectChain returns the redirects followed to get resp, oldest first
+func redirectChain(resp *http.Response) []Redirect {
+	if resp.Request == nil {
+		return nil
+	}
+	var chain []Redirect
+	for r := resp.Request.Response; r != nil && r.Request != nil; r = r.Request.Response {
+		chain = append(chain, Redirect{URL: r.Request.URL.String(), StatusCode: r.StatusCode})
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// RawBody is a request body sent as it is, without encoding. Hooks can set it
+// to replace the body with bytes they built or signed.
+type RawBody []byte
+
+// BodyBytes encodes the body the way it would be sent, with the encoder
+// registered for the Content-Type, and replaces it with the result as a
+// RawBody. Use it in hooks that sign or rewrite the encoded body; readers and
+// uploads are read into memory.
+func (r *Request) BodyBytes() ([]byte, error) {
+	if raw, ok := r.Body.(RawBody); ok {
+		return raw, nil
+	}
+	enc, _ := lookupCodec(r.encoders, headerValue(r.Headers, "Content-Type"))
+	body, err := newRequestBody(r.Body, enc)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	rc, _, err := body.source()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if body.contentType != "" && (body.fixedType || !hasHeader(r.Headers, "Content-Type")) {
+		for k := range r.Headers {
+			if http.CanonicalHeaderKey(k) == "Content-Type" {
+				delete(r.Headers, k)
+			}
+		}
+		r.Headers["Content-Type"] = body.contentType
+	}
+	r.Body = RawBody(data)
+	return data, nil
+}
+
+// SetQueryParam sets a query parameter of the request URL, replacing its
+// values. URL is final when hooks run, so this and direct changes to it are
+// what is sent.
+func (r *Request) SetQueryParam(key, value string) error {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("invalid request URL: %w", err)
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	r.URL = u.String()
+	return nil
+}
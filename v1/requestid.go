@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// RequestIDHeader is the header request IDs are sent in by default
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying id, sent as the request ID
+// of requests made with it, e.g. the ID of the incoming request being served
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// SetRequestID gives every request an ID for cross-service correlation, sent
+// in the X-Request-ID header. The ID is taken from the header if the request
+// sets it, then from the context (see ContextWithRequestID), and generated as
+// a random UUID otherwise. It stays the same across retries and is exposed as
+// Request.ID, Response.RequestID and HTTPError.RequestID and in log lines.
+func (c *Client) SetRequestID(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestIDHeader = ""
+	if enabled {
+		c.requestIDHeader = RequestIDHeader
+	}
+	return c
+}
+
+// SetRequestIDHeader enables request IDs like SetRequestID, sent in header
+// instead of X-Request-ID
+func (c *Client) SetRequestIDHeader(header string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestIDHeader = header
+	return c
+}
+
+// assignRequestID sets the ID of req and its header
+func (c *Client) assignRequestID(req *Request) {
+	id := headerValue(req.Headers, c.requestIDHeader)
+	if id == "" {
+		if id = RequestIDFromContext(req.Context); id == "" {
+			id = newUUID()
+		}
+		req.Headers[c.requestIDHeader] = id
+	}
+	req.ID = id
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// logRequestID returns the request ID of req to append to log lines
+func logRequestID(req *Request) string {
+	if req.ID == "" {
+		return ""
+	}
+	return " [request ID " + req.ID + "]"
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Respond answers r with resp instead of sending it, e.g. from a cache or a
+// stub. Call it from a before request hook: the request then skips the
+// network, retries, limits and middleware, while after response hooks still
+// run and a non-2xx status still fails with an *HTTPError.
+func (r *Request) Respond(resp *Response) {
+	r.response = resp
+}
+
+// respond delivers the response set with Respond as if it had been received
+func (c *Client) respond(muxReq *Request) (*http.Response, *Response, error) {
+	muxResp := muxReq.response
+	header := make(http.Header, len(muxResp.Headers))
+	for k, vs := range muxResp.Headers {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+	muxResp.Headers = header
+	muxResp.RequestID = muxReq.ID
+	if muxResp.Raw == nil {
+		muxResp.Raw = &http.Response{
+			Status:        fmt.Sprintf("%d %s", muxResp.StatusCode, http.StatusText(muxResp.StatusCode)),
+			StatusCode:    muxResp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(muxResp.Body)),
+			ContentLength: int64(len(muxResp.Body)),
+		}
+	}
+	resp := muxResp.Raw
+	if muxReq.opts.stream && muxResp.Stream == nil {
+		streamBuffered(resp, muxResp)
+	}
+
+	// like received responses, error statuses go through the hooks too
+	if err := c.afterResponse(muxReq, muxResp); err != nil {
+		return resp, muxResp, fmt.Errorf("after response hook failed: %w", err)
+	}
+	if !succeeded(muxReq, muxResp) {
+		httpErr := newHTTPError(muxReq, muxResp)
+		c.decodeError(httpErr, muxResp)
+		return resp, muxResp, httpErr
+	}
+	return resp, muxResp, nil
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// IsSuccess reports whether the status code is 2xx
+func (r *Response) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// IsRedirect reports whether the status code is 3xx
+func (r *Response) IsRedirect() bool {
+	return r.StatusCode >= 300 && r.StatusCode < 400
+}
+
+// IsClientError reports whether the status code is 4xx
+func (r *Response) IsClientError() bool {
+	return r.StatusCode >= 400 && r.StatusCode < 500
+}
+
+// IsServerError reports whether the status code is 5xx
+func (r *Response) IsServerError() bool {
+	return r.StatusCode >= 500
+}
+
+// String returns the body as a string
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// Bytes returns the body
+func (r *Response) Bytes() []byte {
+	return r.Body
+}
+
+// Header returns the first value of the response header key
+func (r *Response) Header(key string) string {
+	return http.Header(r.Headers).Get(key)
+}
+
+// Cookies parses the cookies set via Set-Cookie headers
+func (r *Response) Cookies() []*http.Cookie {
+	return (&http.Response{Header: http.Header(r.Headers)}).Cookies()
+}
+
+// Location returns the URL of the Location header, resolved against the request URL.
+// It returns http.ErrNoLocation if the header is absent.
+func (r *Response) Location() (*url.URL, error) {
+	loc := r.Header("Location")
+	if loc == "" {
+		return nil, http.ErrNoLocation
+	}
+	if r.Raw != nil && r.Raw.Request != nil && r.Raw.Request.URL != nil {
+		return r.Raw.Request.URL.Parse(loc)
+	}
+	return url.Parse(loc)
+}
+
+// SaveToFile writes the body to path, creating or truncating the file
+func (r *Response) SaveToFile(path string) error {
+	return os.WriteFile(path, r.Body, 0o644)
+}
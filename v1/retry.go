@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrAttemptTimeout is returned when a single attempt exceeds the attempt
+// timeout while the overall request context is still alive
+var ErrAttemptTimeout = errors.New("attempt timed out")
+
+type attemptKey struct{}
+
+// AttemptFromContext returns the 1-based number of the attempt a request
+// passed to middleware belongs to, or 0 outside of muxet
+func AttemptFromContext(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// RetryPolicy decides whether a failed attempt should be retried.
+// resp is nil when the attempt failed with a transport error.
+type RetryPolicy func(resp *Response, err error) bool
+
+// DefaultRetryPolicy retries network errors, 429 and 5xx responses.
+// Other 4xx responses fail fast.
+func DefaultRetryPolicy(resp *Response, err error) bool {
+	if errors.Is(err, ErrAttemptTimeout) {
+		return true
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if resp == nil {
+		return false
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// attemptError wraps err with ErrAttemptTimeout when the attempt context
+// expired but the parent context did not
+func attemptError(attemptCtx, parent context.Context, err error) error {
+	if parent.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrAttemptTimeout, err)
+	}
+	return err
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response,
+// which holds either a number of seconds or an HTTP-date
+func retryAfter(resp *Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	v := http.Header(resp.Headers).Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// isIdempotent reports whether a request may be retried automatically:
+// its method is idempotent, or it carries an Idempotency-Key header
+func isIdempotent(method string, headers map[string]string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == "Idempotency-Key" && v != "" {
+			return true
+		}
+	}
+	return false
+}
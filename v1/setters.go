@@ -1,43 +1,412 @@
 package v1
 
-import "time"
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"time"
+)
 
 func (c *Client) SetTimeout(d time.Duration) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.timeout = d
 	return c
 }
 
 func (c *Client) SetHeader(key, value string) *Client {
-	c.headers[key] = value
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	headers := maps.Clone(c.headers)
+	headers[key] = value
+	c.headers = headers
 	return c
 }
 
 func (c *Client) SetLogger(l Logger) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.logger = l
 	return c
 }
 
+// SetBaseURL sets the base URL that relative request URLs are resolved
+// against. A unix:// URL, like "unix:///var/run/docker.sock", sends requests
+// to that socket, see SetUnixSocket, until another base URL is set.
 func (c *Client) SetBaseURL(base string) *Client {
-	c.BaseURL = base
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setBaseURL(base)
 	return c
 }
 
 func (c *Client) SetMaxRetries(n int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.maxRetries = n
 	return c
 }
 
+// SetBackoff sets the base delay of the default exponential backoff
 func (c *Client) SetBackoff(d time.Duration) *Client {
-	c.backoff = d
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.backoff = ExponentialBackoff{Base: d}
+	return c
+}
+
+// SetBackoffStrategy sets the strategy computing delays between retries
+func (c *Client) SetBackoffStrategy(b Backoff) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.backoff = b
 	return c
 }
 
 func (c *Client) SetBeforeRequestHook(fn func(*Request) error) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.BeforeRequest = fn
 	return c
 }
 
 func (c *Client) SetAfterResponseHook(fn func(*Response) error) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.AfterResponse = fn
 	return c
 }
+
+// SetHTTPClient replaces the underlying HTTPDoer, e.g. a preconfigured *http.Client
+func (c *Client) SetHTTPClient(d HTTPDoer) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = d
+	return c
+}
+
+// SetTransport sets the RoundTripper used by the underlying *http.Client.
+// When a custom non-*http.Client HTTPDoer is installed it is replaced.
+func (c *Client) SetTransport(rt http.RoundTripper) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hc := &http.Client{}
+	if existing, ok := c.client.(*http.Client); ok {
+		cp := *existing
+		hc = &cp
+	}
+	hc.Transport = rt
+	c.client = hc
+	return c
+}
+
+// SetCookieJar sets the cookie jar of the underlying *http.Client, e.g. a
+// cookiejar.Jar or a FileCookieJar; nil disables cookies. When a custom
+// non-*http.Client HTTPDoer is installed it is replaced.
+func (c *Client) SetCookieJar(jar http.CookieJar) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hc := &http.Client{}
+	if existing, ok := c.client.(*http.Client); ok {
+		cp := *existing
+		hc = &cp
+	}
+	hc.Jar = jar
+	c.client = hc
+	return c
+}
+
+// SetRetryPolicy sets the policy deciding which failures are retried
+func (c *Client) SetRetryPolicy(p RetryPolicy) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retryPolicy = p
+	return c
+}
+
+// SetMaxRetryAfter caps how long a server-provided Retry-After may delay a retry
+func (c *Client) SetMaxRetryAfter(d time.Duration) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRetryAfter = d
+	return c
+}
+
+// SetMaxElapsedTime stops retrying once the total time spent on a request
+// (including the next backoff) would exceed d, regardless of maxRetries
+func (c *Client) SetMaxElapsedTime(d time.Duration) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxElapsed = d
+	return c
+}
+
+// SetRetryBudget shares a retry budget across all requests of the client
+func (c *Client) SetRetryBudget(b *RetryBudget) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retryBudget = b
+	return c
+}
+
+// SetAttemptTimeout bounds each individual attempt, derived from the request
+// context, so a single slow attempt cannot consume the overall deadline
+func (c *Client) SetAttemptTimeout(d time.Duration) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attemptTimeout = d
+	return c
+}
+
+func (c *Client) SetOnRetryHook(fn func(attempt int, req *Request, resp *Response, err error)) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OnRetry = fn
+	return c
+}
+
+// SetOnErrorHook sets a hook called once when a request fails for good, e.g.
+// to report errors centrally
+func (c *Client) SetOnErrorHook(fn func(req *Request, resp *Response, err error)) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OnError = fn
+	return c
+}
+
+// SetCircuitBreaker enables per-host circuit breaking
+func (c *Client) SetCircuitBreaker(b *CircuitBreaker) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.breaker = b
+	return c
+}
+
+// SetHedging enables hedged GET requests: when no response has arrived
+// within delay, another concurrent copy is sent, up to maxHedges extra copies
+func (c *Client) SetHedging(delay time.Duration, maxHedges int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hedgeDelay = delay
+	c.maxHedges = maxHedges
+	return c
+}
+
+// SetBaseURLs sets a primary base URL followed by mirrors. When a host fails,
+// subsequent attempts go to the next healthy mirror; failed hosts are skipped
+// for the cool-down period (30s by default, see SetFailoverCoolDown).
+func (c *Client) SetBaseURLs(bases []string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	parsed := make([]*url.URL, 0, len(bases))
+	for _, b := range bases {
+		u, err := url.Parse(b)
+		if err != nil || u.Host == "" {
+			c.logf(context.Background(), slog.LevelWarn, "Ignoring invalid base URL", []slog.Attr{slog.String("base_url", b)},
+				"Ignoring invalid base URL %q", b)
+			continue
+		}
+		parsed = append(parsed, u)
+	}
+	if len(parsed) == 0 {
+		c.failover = nil
+		return c
+	}
+	c.setBaseURL(parsed[0].String())
+	c.failover = newFailover(parsed, c.failoverCoolDown)
+	return c
+}
+
+// SetFailoverCoolDown sets how long a failed base URL is skipped
+func (c *Client) SetFailoverCoolDown(d time.Duration) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failoverCoolDown = d
+	if c.failover != nil {
+		c.failover.mu.Lock()
+		c.failover.coolDown = d
+		c.failover.mu.Unlock()
+	}
+	return c
+}
+
+// SetRateLimit limits outgoing requests to rps per second with bursts of up
+// to burst requests. Requests block until allowed unless non-blocking mode is on.
+// A non-positive rps disables the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimiter().configure(rps, burst)
+	return c
+}
+
+// SetRateLimitPerHost keeps a separate rate limit bucket for every host
+func (c *Client) SetRateLimitPerHost(perHost bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rl := c.rateLimiter()
+	rl.mu.Lock()
+	rl.perHost = perHost
+	rl.mu.Unlock()
+	return c
+}
+
+// SetRateLimitNonBlocking makes rate-limited requests fail with ErrRateLimited instead of waiting
+func (c *Client) SetRateLimitNonBlocking(nonBlocking bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rl := c.rateLimiter()
+	rl.mu.Lock()
+	rl.nonBlocking = nonBlocking
+	rl.mu.Unlock()
+	return c
+}
+
+// SetAdaptiveThrottling paces requests using the quota hosts advertise in
+// X-RateLimit-* style headers: requests are spread out as the quota runs low
+// and held back until the reset once it is exhausted (up to the Retry-After cap)
+func (c *Client) SetAdaptiveThrottling(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.throttle.mu.Lock()
+	c.throttle.enabled = enabled
+	c.throttle.mu.Unlock()
+	return c
+}
+
+// SetDeduplicateGETs coalesces concurrent GETs with the same URL and headers
+// into a single upstream request whose response is shared with all callers.
+// The shared request runs under the context of the first caller.
+func (c *Client) SetDeduplicateGETs(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !enabled {
+		c.dedup = nil
+	} else if c.dedup == nil {
+		c.dedup = &flightGroup{}
+	}
+	return c
+}
+
+// SetMaxConcurrency caps the number of requests in flight across the client.
+// Further requests queue until a slot frees up or their context is done.
+func (c *Client) SetMaxConcurrency(n int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var s semaphore
+	if n > 0 {
+		s = make(semaphore, n)
+	}
+	c.concurrency.mu.Lock()
+	c.concurrency.total = s
+	c.concurrency.mu.Unlock()
+	return c
+}
+
+// SetMaxConcurrencyPerHost caps the number of requests in flight to a single host
+func (c *Client) SetMaxConcurrencyPerHost(n int) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.concurrency.mu.Lock()
+	c.concurrency.perHost = n
+	c.concurrency.hosts = nil
+	c.concurrency.mu.Unlock()
+	return c
+}
+
+// SetErrorDecoder decodes non-2xx responses into API-specific errors, exposed
+// as HTTPError.Err and reachable through errors.As on the returned error
+func (c *Client) SetErrorDecoder(fn ErrorDecoder) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errorDecoder = fn
+	return c
+}
+
+// SetErrorModel decodes every non-2xx JSON body into a new value of model's type,
+// e.g. SetErrorModel(&APIError{}). If the type implements error it is exposed
+// through errors.As, otherwise the decoded value is available as HTTPError.Model.
+func (c *Client) SetErrorModel(model any) *Client {
+	return c.SetErrorModelForStatus(300, 599, model)
+}
+
+// SetErrorModelForStatus registers an error model for status codes in [from, to].
+// The narrowest matching range wins.
+func (c *Client) SetErrorModelForStatus(from, to int, model any) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := reflect.TypeOf(model)
+	if t == nil {
+		return c
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	c.errorModels = append(slices.Clip(c.errorModels), errorModel{min: from, max: to, typ: t})
+	return c
+}
+
+// SetCompressRequests gzips the bodies of all requests and sets Content-Encoding: gzip
+func (c *Client) SetCompressRequests(enabled bool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compressRequests = enabled
+	return c
+}
+
+// SetQueryParam sets a query parameter sent with every request. Parameters in
+// the request URL or set per request take precedence.
+func (c *Client) SetQueryParam(key, value string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	params := maps.Clone(c.queryParams)
+	params.Set(key, value)
+	c.queryParams = params
+	return c
+}
+
+// SetQueryParams sets several query parameters sent with every request
+func (c *Client) SetQueryParams(params map[string]string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	query := maps.Clone(c.queryParams)
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	c.queryParams = query
+	return c
+}
+
+// SetPathParam sets the value of {name} placeholders in request URLs, e.g.
+// "/users/{id}". The value is path-escaped, so it can't alter the path.
+func (c *Client) SetPathParam(name, value string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	params := maps.Clone(c.pathParams)
+	params[name] = value
+	c.pathParams = params
+	return c
+}
+
+// SetPathParams sets several path parameters
+func (c *Client) SetPathParams(params map[string]string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pathParams := maps.Clone(c.pathParams)
+	maps.Copy(pathParams, params)
+	c.pathParams = pathParams
+	return c
+}
+
+// SetURLJoinMode sets how request URLs are joined with the base URL
+// (JoinResolve by default)
+func (c *Client) SetURLJoinMode(mode URLJoinMode) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.joinMode = mode
+	return c
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// SlowRequest describes a request that took longer than the slow request
+// threshold
+type SlowRequest struct {
+	Method    string
+	URL       string
+	RequestID string
+	// StatusCode is the status of the last response, 0 if there was none
+	StatusCode int
+	// Duration covers all attempts and the delays between them
+	Duration time.Duration
+	Attempts int
+	// Timings breaks down the last attempt
+	Timings Timings
+}
+
+// SetSlowRequestThreshold reports requests taking longer than d, retries
+// included, to fn, or logs them through the logger or the standard logger if
+// fn is nil. 0 disables it.
+func (c *Client) SetSlowRequestThreshold(d time.Duration, fn func(SlowRequest)) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slowThreshold = d
+	c.onSlowRequest = fn
+	return c
+}
+
+// reportSlow reports the request if it took longer than the threshold
+func (c *Client) reportSlow(muxReq *Request, resp *Response, attempts int, elapsed time.Duration) {
+	if c.slowThreshold <= 0 || elapsed <= c.slowThreshold {
+		return
+	}
+	slow := SlowRequest{
+		Method:    muxReq.Method,
+		URL:       muxReq.URL,
+		RequestID: muxReq.ID,
+		Duration:  elapsed,
+		Attempts:  attempts,
+	}
+	if resp != nil {
+		slow.StatusCode = resp.StatusCode
+		slow.Timings = resp.Timings
+	}
+
+	if c.onSlowRequest == nil {
+		attrs := requestAttrs(muxReq,
+			slog.Int("status", slow.StatusCode),
+			slog.Duration("duration", elapsed),
+			slog.Int("attempts", attempts),
+			slog.Group("timings",
+				slog.Duration("dns", slow.Timings.DNSLookup),
+				slog.Duration("connect", slow.Timings.Connect),
+				slog.Duration("tls", slow.Timings.TLSHandshake),
+				slog.Duration("ttfb", slow.Timings.TimeToFirstByte),
+				slog.Duration("total", slow.Timings.Total),
+				slog.Bool("reused", slow.Timings.ConnReused)))
+		c.printf(muxReq.Context, slog.LevelWarn, "Slow request", attrs,
+			"Slow request: %s %s took %s over %d attempts (%s)%s",
+			slow.Method, slow.URL, elapsed.Round(time.Millisecond), attempts, slow.Timings, logRequestID(muxReq))
+		return
+	}
+	err := callHook(func(s SlowRequest) error {
+		c.onSlowRequest(s)
+		return nil
+	}, slow)
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		c.logf(muxReq.Context, slog.LevelError, "Slow request hook panicked",
+			requestAttrs(muxReq, slog.Any("panic", panicErr.Value), slog.String("stack", string(panicErr.Stack))),
+			"Slow request hook panicked: %v\n%s", panicErr.Value, panicErr.Stack)
+	}
+}
@@ -0,0 +1,209 @@
+// Package soap calls SOAP 1.1 and 1.2 services with a muxet client. It wraps
+// payloads in an envelope and unwraps the response body or fault.
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// Version is the SOAP protocol version
+type Version int
+
+// Supported SOAP versions
+const (
+	V11 Version = iota
+	V12
+)
+
+// Envelope namespaces
+const (
+	NamespaceV11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	NamespaceV12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// ErrNoBody is returned when the response envelope has no body element
+var ErrNoBody = errors.New("soap: response has no body")
+
+// Client calls operations on a single SOAP endpoint
+type Client struct {
+	c        *muxet.Client
+	endpoint string
+	version  Version
+
+	// Header is marshaled into the envelope header of every call, e.g. for WS-Security
+	Header any
+}
+
+// New creates a SOAP client for endpoint, relative to the base URL of c
+func New(c *muxet.Client, endpoint string, version Version) *Client {
+	return &Client{c: c, endpoint: endpoint, version: version}
+}
+
+// Fault is a SOAP fault of either version
+type Fault struct {
+	// Code is faultcode (1.1) or Code/Value (1.2)
+	Code string
+	// Reason is faultstring (1.1) or the first Reason/Text (1.2)
+	Reason string
+	// Actor is faultactor (1.1) or Role (1.2)
+	Actor string
+	// Detail is the raw XML content of the detail element
+	Detail string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("soap fault: %s: %s", f.Code, f.Reason)
+}
+
+type fault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+	Actor  string `xml:"faultactor"`
+	Detail struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"detail"`
+
+	Code12 struct {
+		Value string `xml:"Value"`
+	} `xml:"Code"`
+	Reason12 struct {
+		Text []string `xml:"Text"`
+	} `xml:"Reason"`
+	Role12   string `xml:"Role"`
+	Detail12 struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"Detail"`
+}
+
+func (f *fault) toFault() *Fault {
+	out := &Fault{Code: f.Code, Reason: f.String, Actor: f.Actor, Detail: f.Detail.Inner}
+	if f.Code12.Value != "" {
+		out.Code = f.Code12.Value
+		out.Actor = f.Role12
+		out.Detail = f.Detail12.Inner
+		if len(f.Reason12.Text) > 0 {
+			out.Reason = f.Reason12.Text[0]
+		}
+	}
+	return out
+}
+
+// Call sends in as the body of an envelope with the given SOAP action and
+// decodes the first element of the response body into out. Faults are
+// returned as *Fault, also when sent with an HTTP error status.
+func (s *Client) Call(ctx context.Context, action string, in, out any, opts ...muxet.RequestOption) error {
+	body, err := s.envelope(in)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	switch s.version {
+	case V12:
+		headers["Content-Type"] = "application/soap+xml; charset=utf-8; action=" + strconv.Quote(action)
+		headers["Accept"] = "application/soap+xml"
+	default:
+		headers["Content-Type"] = "text/xml; charset=utf-8"
+		headers["Accept"] = "text/xml"
+		headers["SOAPAction"] = strconv.Quote(action)
+	}
+
+	var raw string
+	_, err = s.c.Post(ctx, s.endpoint, bytes.NewReader(body), &raw, headers, opts...)
+	if err != nil {
+		var httpErr *muxet.HTTPError
+		if !errors.As(err, &httpErr) {
+			return err
+		}
+		if f := parseFault(httpErr.Body); f != nil {
+			return f
+		}
+		return err
+	}
+	return unwrap([]byte(raw), out)
+}
+
+// envelope marshals in into a complete SOAP envelope
+func (s *Client) envelope(in any) ([]byte, error) {
+	ns := NamespaceV11
+	if s.version == V12 {
+		ns = NamespaceV12
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<soap:Envelope xmlns:soap="` + ns + `">`)
+	if s.Header != nil {
+		buf.WriteString("<soap:Header>")
+		if err := xml.NewEncoder(&buf).Encode(s.Header); err != nil {
+			return nil, fmt.Errorf("soap: failed to encode header: %w", err)
+		}
+		buf.WriteString("</soap:Header>")
+	}
+	buf.WriteString("<soap:Body>")
+	if in != nil {
+		if err := xml.NewEncoder(&buf).Encode(in); err != nil {
+			return nil, fmt.Errorf("soap: failed to encode body: %w", err)
+		}
+	}
+	buf.WriteString("</soap:Body></soap:Envelope>")
+	return buf.Bytes(), nil
+}
+
+// unwrap decodes the first element inside the envelope body into out, or
+// returns it as *Fault if it is one
+func unwrap(data []byte, out any) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	inBody := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return ErrNoBody
+		}
+		if err != nil {
+			return fmt.Errorf("soap: failed to decode response: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !inBody {
+				inBody = t.Name.Local == "Body" && (t.Name.Space == NamespaceV11 || t.Name.Space == NamespaceV12)
+				continue
+			}
+			if t.Name.Local == "Fault" {
+				var f fault
+				if err := d.DecodeElement(&f, &t); err != nil {
+					return fmt.Errorf("soap: failed to decode fault: %w", err)
+				}
+				return f.toFault()
+			}
+			if out == nil {
+				return nil
+			}
+			if err := d.DecodeElement(out, &t); err != nil {
+				return fmt.Errorf("soap: failed to decode response: %w", err)
+			}
+			return nil
+		case xml.EndElement:
+			if inBody {
+				// empty body
+				return nil
+			}
+		}
+	}
+}
+
+// parseFault returns the fault in an error response, or nil if there is none
+func parseFault(data []byte) *Fault {
+	var f *Fault
+	if errors.As(unwrap(data, nil), &f) {
+		return f
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Package sse consumes Server-Sent Events (text/event-stream) with a muxet
+// client, reconnecting with Last-Event-ID when the connection drops.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+// DefaultRetry is the reconnection delay until the server sends its own
+const DefaultRetry = 3 * time.Second
+
+// Event is a single server-sent event
+type Event struct {
+	// ID is the last event ID seen on the stream
+	ID string
+	// Event is the event type, "message" unless the server names it
+	Event string
+	// Data is the payload; multiple data lines are joined with newlines
+	Data string
+	// Retry is the reconnection delay requested with this event, if any
+	Retry time.Duration
+}
+
+// Subscribe connects to url and calls fn for every event until ctx is done,
+// fn returns an error or the server answers 204 No Content. Dropped
+// connections and retryable failures are re-established after the retry
+// delay, sending the last event ID so the server can resume the stream.
+func Subscribe(ctx context.Context, c *muxet.Client, url string, fn func(Event) error, opts ...muxet.RequestOption) error {
+	s := &stream{retry: DefaultRetry}
+	for {
+		err := s.connect(ctx, c, url, fn, opts)
+		var done *stopError
+		switch {
+		case errors.As(err, &done):
+			return done.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !errors.Is(err, errDropped) && !muxet.IsRetryable(err):
+			return err
+		}
+
+		t := time.NewTimer(s.retry)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Events is like Subscribe but delivers events on a channel. The error that
+// ended the subscription, nil after a 204, is sent on errc once events is closed.
+func Events(ctx context.Context, c *muxet.Client, url string, opts ...muxet.RequestOption) (events <-chan Event, errc <-chan error) {
+	ch := make(chan Event)
+	ec := make(chan error, 1)
+	go func() {
+		err := Subscribe(ctx, c, url, func(e Event) error {
+			select {
+			case ch <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, opts...)
+		close(ch)
+		ec <- err
+	}()
+	return ch, ec
+}
+
+// errDropped marks a connection that ended while reading events
+var errDropped = errors.New("event stream dropped")
+
+// stopError ends the subscription without reconnecting
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string { return fmt.Sprint(e.err) }
+
+// stream holds the state that survives reconnects. The id field only takes
+// effect once its event is dispatched, so idBuf holds it until then.
+type stream struct {
+	lastID string
+	idBuf  string
+	retry  time.Duration
+}
+
+// connect reads events from a single connection until it ends
+func (s *stream) connect(ctx context.Context, c *muxet.Client, url string, fn func(Event) error, opts []muxet.RequestOption) error {
+	headers := map[string]string{
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+	if s.lastID != "" {
+		headers["Last-Event-ID"] = s.lastID
+	}
+	resp, err := c.DoStream(ctx, http.MethodGet, url, nil, headers, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Stream.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return &stopError{}
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header("Content-Type")); mt != "text/event-stream" {
+		return &stopError{fmt.Errorf("unexpected Content-Type %q", resp.Header("Content-Type"))}
+	}
+
+	// an id from an event that was cut off doesn't count
+	s.idBuf = s.lastID
+	r := bufio.NewReader(resp.Stream)
+	var data strings.Builder
+	var event Event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			// a partial event is discarded, the server resends it after reconnecting
+			if err == io.EOF {
+				return errDropped
+			}
+			return fmt.Errorf("%w: %w", errDropped, err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			s.lastID = s.idBuf
+			if data.Len() > 0 {
+				event.ID = s.lastID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				if err := fn(event); err != nil {
+					return &stopError{err}
+				}
+			}
+			data.Reset()
+			event = Event{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.idBuf = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+				event.Retry = s.retry
+			}
+		}
+	}
+}
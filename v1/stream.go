@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// WithStreamBody leaves the body of a successful response unread. It is exposed
+// as Response.Stream (and the Body of the returned *http.Response), which the
+// caller must close. Error responses are still buffered.
+func WithStreamBody() RequestOption {
+	return func(o *requestOptions) {
+		o.stream = true
+	}
+}
+
+// DoStream performs a request without buffering the response body, for large
+// downloads and long-lived streaming endpoints. The caller must close resp.Stream.
+func (c *Client) DoStream(ctx context.Context, method, url string, body any, headers map[string]string, opts ...RequestOption) (*Response, error) {
+	_, resp, err := c.do(ctx, method, url, body, headers, append(opts[:len(opts):len(opts)], WithStreamBody()))
+	return resp, err
+}
+
+// streamBuffered exposes the buffered body of a response as its Stream, for
+// streamed requests answered without an unread body, like mocks and 304 Not
+// Modified
+func streamBuffered(resp *http.Response, muxResp *Response) {
+	muxResp.Stream, muxResp.Body = io.NopCloser(bytes.NewReader(muxResp.Body)), nil
+	resp.Body = muxResp.Stream
+}
+
+// closeHook runs fns once the wrapped body is closed
+type closeHook struct {
+	io.ReadCloser
+	once sync.Once
+	fns  []func()
+}
+
+func onClose(rc io.ReadCloser, fns ...func()) io.ReadCloser {
+	return &closeHook{ReadCloser: rc, fns: fns}
+}
+
+func (h *closeHook) Close() error {
+	err := h.ReadCloser.Close()
+	h.once.Do(func() {
+		for _, fn := range h.fns {
+			fn()
+		}
+	})
+	return err
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Quota is the rate limit quota last advertised by a host
+type Quota struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	Updated   time.Time
+}
+
+// rate limit header variants: X-RateLimit-* (GitHub and most APIs),
+// X-Rate-Limit-* (Twitter style) and the IETF RateLimit-* draft
+var quotaHeaders = []struct {
+	limit, remaining, reset string
+}{
+	{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+	{"X-Rate-Limit-Limit", "X-Rate-Limit-Remaining", "X-Rate-Limit-Reset"},
+	{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
+}
+
+// throttle tracks advertised quotas per host and paces requests as they run out
+type throttle struct {
+	mu      sync.Mutex
+	enabled bool
+	quotas  map[string]*Quota
+}
+
+// parseQuota extracts the advertised quota from response headers
+func parseQuota(h http.Header, now time.Time) (Quota, bool) {
+	for _, names := range quotaHeaders {
+		remaining, err := strconv.Atoi(h.Get(names.remaining))
+		if err != nil {
+			continue
+		}
+		q := Quota{Limit: -1, Remaining: remaining, Updated: now}
+		if limit, err := strconv.Atoi(h.Get(names.limit)); err == nil {
+			q.Limit = limit
+		}
+		if reset, err := strconv.ParseInt(h.Get(names.reset), 10, 64); err == nil {
+			// large values are epoch seconds (GitHub), small ones seconds until reset
+			if reset > 1_000_000_000 {
+				q.Reset = time.Unix(reset, 0)
+			} else {
+				q.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return q, true
+	}
+	return Quota{}, false
+}
+
+func (t *throttle) observe(host string, h http.Header) {
+	q, ok := parseQuota(h, time.Now())
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.quotas == nil {
+		t.quotas = make(map[string]*Quota)
+	}
+	t.quotas[host] = &q
+}
+
+func (t *throttle) quota(host string) (Quota, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q, ok := t.quotas[host]
+	if !ok {
+		return Quota{}, false
+	}
+	return *q, true
+}
+
+// delay returns how long to hold back the next request to host. Once the quota is
+// exhausted requests wait for the reset; below 10% of the limit they are spread
+// evenly over the remaining window. Sending is accounted for in the local quota.
+func (t *throttle) delay(host string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return 0
+	}
+	q, ok := t.quotas[host]
+	if !ok || q.Reset.IsZero() || !now.Before(q.Reset) {
+		return 0
+	}
+	window := q.Reset.Sub(now)
+	var d time.Duration
+	switch {
+	case q.Remaining <= 0:
+		d = window
+	case q.Limit > 0 && q.Remaining*10 < q.Limit:
+		d = window / time.Duration(q.Remaining+1)
+	}
+	q.Remaining--
+	return d
+}
+
+// wait holds back a request to host according to its advertised quota.
+// Waits longer than limit fail with ErrRateLimited instead.
+func (t *throttle) wait(ctx context.Context, host string, limit time.Duration) error {
+	d := t.delay(host, time.Now())
+	if d <= 0 {
+		return nil
+	}
+	if d > limit {
+		return fmt.Errorf("%w: quota for %s exhausted for another %s", ErrRateLimited, host, d.Round(time.Second))
+	}
+	return sleep(ctx, d)
+}
+
+// RateLimitQuota returns the quota last advertised by host via rate limit headers
+func (c *Client) RateLimitQuota(host string) (Quota, bool) {
+	return c.throttle.quota(host)
+}
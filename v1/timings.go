@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks down the duration of the attempt that produced a response.
+// The connection phases are zero when a pooled connection was reused.
+// Hedged requests don't collect timings.
+type Timings struct {
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// TimeToFirstByte runs from sending the request to the first byte of the
+	// response, connection setup included
+	TimeToFirstByte time.Duration
+	// Total runs until the body was read, or until the headers arrived for
+	// streamed responses
+	Total      time.Duration
+	ConnReused bool
+}
+
+// timingTrace collects Timings through httptrace. Callbacks may run on
+// other goroutines, even after the response arrived.
+type timingTrace struct {
+	mu                            sync.Mutex
+	start                         time.Time
+	dnsStart, connStart, tlsStart time.Time
+	timings                       Timings
+}
+
+func (t *timingTrace) clientTrace() *httptrace.ClientTrace {
+	since := func(from *time.Time, to *time.Duration) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !from.IsZero() {
+			*to = time.Since(*from)
+		}
+	}
+	mark := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*at = time.Now()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { since(&t.dnsStart, &t.timings.DNSLookup) },
+		ConnectStart:      func(string, string) { mark(&t.connStart) },
+		ConnectDone:       func(string, string, error) { since(&t.connStart, &t.timings.Connect) },
+		TLSHandshakeStart: func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { since(&t.tlsStart, &t.timings.TLSHandshake) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.ConnReused = info.Reused
+		},
+		GotFirstResponseByte: func() { since(&t.start, &t.timings.TimeToFirstByte) },
+	}
+}
+
+// result returns the collected timings with the total duration of the attempt
+func (t *timingTrace) result(total time.Duration) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timings := t.timings
+	timings.Total = total
+	return timings
+}
+
+func (t Timings) String() string {
+	round := func(d time.Duration) time.Duration { return d.Round(time.Microsecond) }
+	s := fmt.Sprintf("dns=%s connect=%s tls=%s ttfb=%s total=%s",
+		round(t.DNSLookup), round(t.Connect), round(t.TLSHandshake), round(t.TimeToFirstByte), round(t.Total))
+	if t.ConnReused {
+		s += " reused"
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// SetTLSConfig replaces the TLS configuration of the transport with a copy
+// of cfg, including root CAs and client certificates set before; nil restores
+// the defaults
+func (c *Client) SetTLSConfig(cfg *tls.Config) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateTransport("TLS config", func(t *http.Transport) {
+		t.TLSClientConfig = cfg.Clone()
+	})
+	return c
+}
+
+// SetRootCAs verifies servers with the certificate authorities of pool
+// instead of the system ones
+func (c *Client) SetRootCAs(pool *x509.CertPool) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateTLS("root CAs", func(cfg *tls.Config) { cfg.RootCAs = pool })
+	return c
+}
+
+// AddRootCAFromPEM trusts the certificate authorities of PEM data, e.g. of a
+// private CA or a self-signed server, on top of the root CAs in use, the
+// system ones by default. Data without certificates is logged and ignored.
+func (c *Client) AddRootCAFromPEM(data []byte) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateTLS("root CAs", func(cfg *tls.Config) {
+		var pool *x509.CertPool
+		if cfg.RootCAs != nil {
+			pool = cfg.RootCAs.Clone()
+		} else if pool, _ = x509.SystemCertPool(); pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			c.logf(context.Background(), slog.LevelWarn, "Ignoring root CAs without certificates", nil,
+				"Ignoring root CAs: no certificate found in PEM data")
+			return
+		}
+		cfg.RootCAs = pool
+	})
+	return c
+}
+
+// SetClientCertificate authenticates to mTLS-protected servers with cert
+func (c *Client) SetClientCertificate(cert tls.Certificate) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateTLS("client certificate", func(cfg *tls.Config) {
+		cfg.Certificates = []tls.Certificate{cert}
+		cfg.GetClientCertificate = nil
+	})
+	return c
+}
+
+// SetClientCert authenticates to mTLS-protected servers with the certificate
+// and key of PEM files, read once. Files that can't be loaded are logged and
+// ignored; see SetClientCertReload for rotated certificates.
+func (c *Client) SetClientCert(certFile, keyFile string) *Client {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.logf(context.Background(), slog.LevelWarn, "Ignoring client certificate", []slog.Attr{slog.String("error", err.Error())},
+			"Ignoring client certificate: %v", err)
+		return c
+	}
+	return c.SetClientCertificate(cert)
+}
+
+// SetClientCertReload is SetClientCert for certificates that rotate: the
+// files are checked at each new TLS connection and read again when they
+// changed. While rotated files can't be loaded, e.g. half written, the last
+// certificate is kept; without one, connections fail with the load error.
+func (c *Client) SetClientCertReload(certFile, keyFile string) *Client {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := r.certificate(); err != nil {
+		c.logf(context.Background(), slog.LevelWarn, "Failed to load client certificate", []slog.Attr{slog.String("error", err.Error())},
+			"Failed to load client certificate: %v", err)
+	}
+	c.updateTLS("client certificate", func(cfg *tls.Config) {
+		cfg.Certificates = nil
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	})
+	return c
+}
+
+// certReloader loads a key pair again when its files are modified
+type certReloader struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	modified, err := latestModTime(r.certFile, r.keyFile)
+	if err == nil && r.cert != nil && modified.Equal(r.modified) {
+		return r.cert, nil
+	}
+	if err == nil {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err == nil {
+			r.cert, r.modified = &cert, modified
+			return r.cert, nil
+		}
+	}
+	if r.cert != nil {
+		return r.cert, nil
+	}
+	return nil, fmt.Errorf("failed to load client certificate: %w", err)
+}
+
+// latestModTime returns when the last of files was modified
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// updateTLS applies fn to a copy of the TLS configuration of the transport,
+// see updateTransport; c.mu must be held
+func (c *Client) updateTLS(setting string, fn func(*tls.Config)) {
+	c.updateTransport(setting, func(t *http.Transport) {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		fn(t.TLSClientConfig)
+	})
+}
@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+)
+
+// Do performs a request and decodes a successful response into a T; an empty
+// body, like that of a 204, leaves the zero T
+func Do[T any](ctx context.Context, c *Client, method, url string, body any, opts ...RequestOption) (T, *Response, error) {
+	var out T
+	_, resp, err := c.do(ctx, method, url, body, nil, opts)
+	if err != nil || len(resp.Body) == 0 && resp.Stream == nil {
+		return out, resp, err
+	}
+	err = c.decode(resp, &out)
+	return out, resp, err
+}
+
+func Get[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) (T, *Response, error) {
+	return Do[T](ctx, c, http.MethodGet, url, nil, opts...)
+}
+
+func Post[T any](ctx context.Context, c *Client, url string, body any, opts ...RequestOption) (T, *Response, error) {
+	return Do[T](ctx, c, http.MethodPost, url, body, opts...)
+}
+
+func Put[T any](ctx context.Context, c *Client, url string, body any, opts ...RequestOption) (T, *Response, error) {
+	return Do[T](ctx, c, http.MethodPut, url, body, opts...)
+}
+
+func Delete[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) (T, *Response, error) {
+	return Do[T](ctx, c, http.MethodDelete, url, nil, opts...)
+}
+
+func Patch[T any](ctx context.Context, c *Client, url string, body any, opts ...RequestOption) (T, *Response, error) {
+	return Do[T](ctx, c, http.MethodPatch, url, body, opts...)
+}
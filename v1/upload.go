@@ -0,0 +1,258 @@
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tusVersion       = "1.0.0"
+	defaultChunkSize = 5 << 20
+)
+
+// ResumableUpload describes an upload using the tus protocol (https://tus.io).
+// The upload URLs are filled in as soon as they are created; persist the struct
+// to resume an interrupted upload later, even after a restart.
+type ResumableUpload struct {
+	// URL is the upload resource, created on first use when empty
+	URL string
+	// Parts are the partial uploads of a parallel upload
+	Parts []string
+	// ChunkSize is the size of each PATCH request (default 5 MiB)
+	ChunkSize int64
+	// Parallel splits the file into this many parts uploaded concurrently and
+	// concatenated by the server (tus concatenation extension)
+	Parallel int
+	// Metadata is sent as Upload-Metadata when the upload is created
+	Metadata map[string]string
+	// OnProgress reports the bytes stored by the server so far
+	OnProgress func(sent, total int64)
+}
+
+// UploadResumable uploads size bytes of src to the tus endpoint in chunks.
+// Failed chunks are retried from the offset the server reports, according to
+// the client's retry settings. When u already holds upload URLs, the upload
+// continues where it stopped instead of starting over.
+func (c *Client) UploadResumable(ctx context.Context, endpoint string, src io.ReaderAt, size int64, u *ResumableUpload, opts ...RequestOption) error {
+	c, _ = c.snapshot(opts)
+	chunk := u.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultChunkSize
+	}
+	progress := &uploadProgress{fn: u.OnProgress, total: size}
+
+	if len(u.Parts) == 0 && (u.Parallel <= 1 || u.URL != "") {
+		resume := u.URL != ""
+		if !resume {
+			url, err := c.tusCreate(ctx, endpoint, size, u.Metadata, "", opts)
+			if err != nil {
+				return err
+			}
+			u.URL = url
+		}
+		return c.tusUpload(ctx, u.URL, io.NewSectionReader(src, 0, size), resume, chunk, progress, opts)
+	}
+
+	if u.URL != "" {
+		// the parts were concatenated already
+		return nil
+	}
+	resume := len(u.Parts) > 0
+	parts := u.Parts
+	if !resume {
+		parts = make([]string, min(int64(u.Parallel), max(size, 1)))
+	}
+	sections := splitSections(src, size, len(parts))
+	if !resume {
+		for i, s := range sections {
+			url, err := c.tusCreate(ctx, endpoint, s.Size(), nil, "partial", opts)
+			if err != nil {
+				return err
+			}
+			parts[i] = url
+		}
+		u.Parts = parts
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(parts))
+	for i, part := range parts {
+		go func() {
+			err := c.tusUpload(ctx, part, sections[i], resume, chunk, progress, opts)
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}()
+	}
+	var firstErr error
+	for range parts {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	url, err := c.tusCreate(ctx, endpoint, -1, u.Metadata, "final;"+strings.Join(parts, " "), opts)
+	if err != nil {
+		return err
+	}
+	u.URL = url
+	return nil
+}
+
+// splitSections splits the first size bytes of src into n sections of about equal size
+func splitSections(src io.ReaderAt, size int64, n int) []*io.SectionReader {
+	sections := make([]*io.SectionReader, n)
+	part := size / int64(n)
+	for i := range n {
+		start := int64(i) * part
+		length := part
+		if i == n-1 {
+			length = size - start
+		}
+		sections[i] = io.NewSectionReader(src, start, length)
+	}
+	return sections
+}
+
+// tusCreate creates an upload of size bytes, or of deferred size for a final
+// concatenation, and returns its URL
+func (c *Client) tusCreate(ctx context.Context, endpoint string, size int64, metadata map[string]string, concat string, opts []RequestOption) (string, error) {
+	headers := map[string]string{"Tus-Resumable": tusVersion}
+	if size >= 0 {
+		headers["Upload-Length"] = strconv.FormatInt(size, 10)
+	}
+	if concat != "" {
+		headers["Upload-Concat"] = concat
+	}
+	if len(metadata) > 0 {
+		headers["Upload-Metadata"] = encodeUploadMetadata(metadata)
+	}
+
+	_, resp, err := c.do(ctx, http.MethodPost, endpoint, nil, headers, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	loc, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	return loc.String(), nil
+}
+
+// tusOffset asks the server how many bytes of the upload it has stored
+func (c *Client) tusOffset(ctx context.Context, url string, opts []RequestOption) (int64, error) {
+	_, resp, err := c.do(ctx, http.MethodHead, url, nil, map[string]string{"Tus-Resumable": tusVersion}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get upload offset: %w", err)
+	}
+	return parseUploadOffset(resp)
+}
+
+// tusUpload sends src to the upload at url chunk by chunk, starting at the
+// offset stored by the server when resuming
+func (c *Client) tusUpload(ctx context.Context, url string, src *io.SectionReader, resume bool, chunk int64, progress *uploadProgress, opts []RequestOption) error {
+	size := src.Size()
+	var offset int64
+	if resume {
+		var err error
+		if offset, err = c.tusOffset(ctx, url, opts); err != nil {
+			return err
+		}
+	}
+	progress.add(offset)
+
+	retries := 0
+	var delay time.Duration
+	for offset < size {
+		n := min(chunk, size-offset)
+		headers := map[string]string{
+			"Tus-Resumable": tusVersion,
+			"Upload-Offset": strconv.FormatInt(offset, 10),
+			"Content-Type":  "application/offset+octet-stream",
+		}
+		_, resp, err := c.do(ctx, http.MethodPatch, url, io.NewSectionReader(src, offset, n), headers, opts)
+		if err == nil {
+			var next int64
+			if next, err = parseUploadOffset(resp); err == nil {
+				progress.add(next - offset)
+				offset, retries, delay = next, 0, 0
+				continue
+			}
+		}
+
+		// the server may have stored part of the chunk, or a conflicting
+		// offset means we are out of sync: ask where to continue
+		conflict := resp != nil && resp.StatusCode == http.StatusConflict
+		if retries >= c.maxRetries || !conflict && (c.retryPolicy == nil || !c.retryPolicy(resp, err)) {
+			return fmt.Errorf("failed to upload chunk at offset %d: %w", offset, err)
+		}
+		retries++
+		if c.backoff != nil {
+			delay = c.backoff.Next(retries-1, delay)
+		}
+		if err := c.clock.Sleep(ctx, delay); err != nil {
+			return err
+		}
+		next, err := c.tusOffset(ctx, url, opts)
+		if err != nil {
+			return err
+		}
+		progress.add(next - offset)
+		offset = next
+	}
+	return nil
+}
+
+func parseUploadOffset(resp *Response) (int64, error) {
+	offset, err := strconv.ParseInt(resp.Header("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, errors.New("missing or invalid Upload-Offset header")
+	}
+	return offset, nil
+}
+
+// encodeUploadMetadata formats metadata as comma separated "key base64(value)" pairs
+func encodeUploadMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + " " + base64.StdEncoding.EncodeToString([]byte(metadata[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// uploadProgress aggregates the progress of concurrent part uploads
+type uploadProgress struct {
+	mu    sync.Mutex
+	fn    func(sent, total int64)
+	total int64
+	sent  int64
+}
+
+func (p *uploadProgress) add(n int64) {
+	if p.fn == nil || n == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent += n
+	p.fn(p.sent, p.total)
+}
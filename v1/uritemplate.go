@@ -0,0 +1,280 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithURIVars sets variables for the URI template of a single request, e.g.
+// "/repos/{owner}/{repo}/issues{?state,labels*}". Values may be strings,
+// numbers, slices or maps; they take precedence over path parameters.
+func WithURIVars(vars map[string]any) RequestOption {
+	return func(o *requestOptions) {
+		if o.uriVars == nil {
+			o.uriVars = make(map[string]any)
+		}
+		for k, v := range vars {
+			o.uriVars[k] = v
+		}
+	}
+}
+
+// ExpandURITemplate expands an RFC 6570 URI template up to level 4. Values
+// may be strings, numbers, booleans, slices or maps with string keys; nil
+// values and empty slices and maps are undefined and expand to nothing.
+func ExpandURITemplate(template string, vars map[string]any) (string, error) {
+	return expandTemplate(template, vars, false)
+}
+
+// templateOp describes how an expression operator expands its variables
+type templateOp struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var templateOps = map[byte]templateOp{
+	'+': {sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// expandTemplate expands template with vars. In strict mode, used for request
+// URLs, simple {name} expressions must be defined and values that are dot
+// segments are escaped, so a path parameter always stays a single segment.
+func expandTemplate(template string, vars map[string]any, strict bool) (string, error) {
+	if !strings.Contains(template, "{") {
+		return template, nil
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated URI template expression at %q", template[start:])
+		}
+		end += start
+		b.WriteString(template[:start])
+		if err := expandExpression(&b, template[start+1:end], vars, strict); err != nil {
+			return "", err
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String(), nil
+}
+
+func expandExpression(b *strings.Builder, expr string, vars map[string]any, strict bool) error {
+	if expr == "" {
+		return fmt.Errorf("empty URI template expression")
+	}
+	op, ok := templateOps[expr[0]]
+	simple := !ok
+	if ok {
+		expr = expr[1:]
+	} else if strings.ContainsRune("=,!@|", rune(expr[0])) {
+		return fmt.Errorf("unsupported URI template operator %q", expr[0])
+	} else {
+		op = templateOp{sep: ","}
+	}
+
+	first := true
+	for spec := range strings.SplitSeq(expr, ",") {
+		name, explode, prefix, err := parseVarSpec(spec)
+		if err != nil {
+			return err
+		}
+		value, defined := templateValue(vars[name])
+		if !defined {
+			if strict && simple {
+				return fmt.Errorf("missing path parameter %q", name)
+			}
+			continue
+		}
+		if first {
+			b.WriteString(op.first)
+			first = false
+		} else {
+			b.WriteString(op.sep)
+		}
+
+		switch v := value.(type) {
+		case string:
+			if op.named {
+				b.WriteString(name)
+				if v == "" {
+					b.WriteString(op.ifEmpty)
+					continue
+				}
+				b.WriteByte('=')
+			}
+			if prefix > 0 && utf8.RuneCountInString(v) > prefix {
+				v = string([]rune(v)[:prefix])
+			}
+			if strict && simple && (v == "." || v == "..") {
+				b.WriteString(strings.ReplaceAll(v, ".", "%2E"))
+				continue
+			}
+			b.WriteString(escapeTemplate(v, op.reserved))
+
+		case []string:
+			if prefix > 0 {
+				return fmt.Errorf("prefix modifier not allowed on list variable %q", name)
+			}
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = escapeTemplate(item, op.reserved)
+				if explode && op.named {
+					items[i] = namedItem(name, items[i], op)
+				}
+			}
+			if !explode {
+				if op.named {
+					b.WriteString(name + "=")
+				}
+				b.WriteString(strings.Join(items, ","))
+				continue
+			}
+			b.WriteString(strings.Join(items, op.sep))
+
+		case [][2]string:
+			if prefix > 0 {
+				return fmt.Errorf("prefix modifier not allowed on map variable %q", name)
+			}
+			items := make([]string, 0, len(v)*2)
+			for _, kv := range v {
+				k, val := escapeTemplate(kv[0], op.reserved), escapeTemplate(kv[1], op.reserved)
+				if !explode {
+					items = append(items, k, val)
+				} else if op.named {
+					items = append(items, namedItem(k, val, op))
+				} else {
+					items = append(items, k+"="+val)
+				}
+			}
+			if !explode {
+				if op.named {
+					b.WriteString(name + "=")
+				}
+				b.WriteString(strings.Join(items, ","))
+				continue
+			}
+			b.WriteString(strings.Join(items, op.sep))
+		}
+	}
+	return nil
+}
+
+func namedItem(name, value string, op templateOp) string {
+	if value == "" {
+		return name + op.ifEmpty
+	}
+	return name + "=" + value
+}
+
+// parseVarSpec splits a variable specification like "name*" or "name:3"
+func parseVarSpec(spec string) (name string, explode bool, prefix int, err error) {
+	if name, ok := strings.CutSuffix(spec, "*"); ok {
+		spec, explode = name, true
+	}
+	name, p, hasPrefix := strings.Cut(spec, ":")
+	if hasPrefix {
+		if explode {
+			return "", false, 0, fmt.Errorf("invalid URI template variable %q", spec)
+		}
+		prefix, err = strconv.Atoi(p)
+		if err != nil || prefix <= 0 || prefix >= 10000 {
+			return "", false, 0, fmt.Errorf("invalid prefix in URI template variable %q", spec)
+		}
+	}
+	if name == "" {
+		return "", false, 0, fmt.Errorf("invalid URI template variable %q", spec)
+	}
+	return name, explode, prefix, nil
+}
+
+// templateValue normalizes v to a string, a list of strings or a list of
+// sorted key-value pairs, and reports whether it is defined
+func templateValue(v any) (any, bool) {
+	switch x := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return x, true
+	case []string:
+		return x, len(x) > 0
+	case fmt.Stringer:
+		return x.String(), true
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return nil, false
+		}
+		items := make([]string, rv.Len())
+		for i := range rv.Len() {
+			items[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return items, true
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return nil, false
+		}
+		pairs := make([][2]string, 0, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			pairs = append(pairs, [2]string{fmt.Sprint(iter.Key().Interface()), fmt.Sprint(iter.Value().Interface())})
+		}
+		slices.SortFunc(pairs, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+		return pairs, true
+	}
+	return fmt.Sprint(rv.Interface()), true
+}
+
+// escapeTemplate percent-encodes s, keeping unreserved characters and, for
+// reserved expansion, reserved characters and existing percent-encodings
+func escapeTemplate(s string, reserved bool) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0:
+			b.WriteByte(c)
+		case reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			b.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
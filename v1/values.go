@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hasTag reports whether v is a struct, or pointer to one, with at least one field tagged tag
+func hasTag(v any, tag string) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if _, ok := t.Field(i).Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeValues flattens a struct into url.Values using the given tag, like
+// `form:"name,omitempty"`. Untagged exported fields use their name, "-" skips
+// a field, embedded structs are inlined and slices become repeated keys.
+//
+// Further options: "comma" joins a slice into a single comma separated value,
+// "int" sends booleans as 1 or 0, and "unix", "unixmilli" and "unixnano"
+// send times as epoch numbers. Times are formatted as RFC 3339 unless a
+// `layout:"2006-01-02"` tag gives another layout.
+func encodeValues(v any, tag string) (url.Values, error) {
+	values := make(url.Values)
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return values, addStruct(values, rv, tag)
+}
+
+func addStruct(values url.Values, rv reflect.Value, tag string) error {
+	t := rv.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if f.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := addStruct(values, fv, tag); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fo := fieldOptions{opts: "," + opts + ",", layout: f.Tag.Get("layout")}
+		if fo.has("omitempty") && fv.IsZero() {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 || fv.Kind() == reflect.Array {
+			var items []string
+			for j := range fv.Len() {
+				s, ok, err := formatValue(fv.Index(j), fo)
+				if err != nil {
+					return fmt.Errorf("field %s: %w", f.Name, err)
+				}
+				if ok {
+					items = append(items, s)
+				}
+			}
+			if fo.has("comma") {
+				if len(items) > 0 {
+					values.Add(name, strings.Join(items, ","))
+				}
+				continue
+			}
+			values[name] = append(values[name], items...)
+			continue
+		}
+		s, ok, err := formatValue(fv, fo)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if ok {
+			values.Add(name, s)
+		}
+	}
+	return nil
+}
+
+// fieldOptions are the tag options of a field, enclosed in commas
+type fieldOptions struct {
+	opts   string
+	layout string
+}
+
+func (fo fieldOptions) has(opt string) bool {
+	return strings.Contains(fo.opts, ","+opt+",")
+}
+
+// formatValue renders a single field value; nil pointers are skipped
+func formatValue(v reflect.Value, fo fieldOptions) (string, bool, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		switch {
+		case fo.has("unix"):
+			return strconv.FormatInt(x.Unix(), 10), true, nil
+		case fo.has("unixmilli"):
+			return strconv.FormatInt(x.UnixMilli(), 10), true, nil
+		case fo.has("unixnano"):
+			return strconv.FormatInt(x.UnixNano(), 10), true, nil
+		case fo.layout != "":
+			return x.Format(fo.layout), true, nil
+		}
+		return x.Format(time.RFC3339), true, nil
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		return string(b), err == nil, err
+	case fmt.Stringer:
+		return x.String(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		if fo.has("int") {
+			if v.Bool() {
+				return "1", true, nil
+			}
+			return "0", true, nil
+		}
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+	}
+	return "", false, fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,346 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// WebSocket message types
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+)
+
+const (
+	opContinuation = 0
+	opClose        = 8
+	opPing         = 9
+	opPong         = 10
+
+	// websocketGUID is appended to the key to compute Sec-WebSocket-Accept (RFC 6455)
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maxMessageSize bounds messages read from the connection
+	maxMessageSize = 32 << 20
+)
+
+// ErrBadHandshake is returned when the server doesn't accept the WebSocket upgrade
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// CloseError is returned by ReadMessage once the peer closed the connection
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Text)
+}
+
+// WebsocketConn is a client WebSocket connection. Reads and writes may run
+// concurrently with each other, but not with themselves.
+type WebsocketConn struct {
+	rwc io.ReadWriteCloser
+	br  *bufio.Reader
+
+	wmu    sync.Mutex
+	closed bool
+}
+
+// Websocket opens a WebSocket connection to url (ws, wss, http or https,
+// relative to the base URL) through the client's transport and middleware, so
+// headers, auth, proxy and TLS settings apply to the handshake as well.
+// ctx bounds the handshake only.
+func (c *Client) Websocket(ctx context.Context, rawURL string, opts ...RequestOption) (*WebsocketConn, *Response, error) {
+	c, ro := c.snapshot(opts)
+	if ctx == nil || ro.timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	fullURL, err := c.requestURL(rawURL, ro)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+
+	hdr := make(map[string]string)
+	for k, v := range c.headers {
+		hdr[k] = v
+	}
+	for k, v := range ro.headers {
+		hdr[k] = v
+	}
+	addCookies(hdr, ro.cookies)
+	muxReq := &Request{Method: http.MethodGet, URL: u.String(), Headers: hdr, Context: ctx, opts: ro}
+	if c.requestIDHeader != "" {
+		c.assignRequestID(muxReq)
+	}
+	if err := c.beforeRequest(muxReq); err != nil {
+		return nil, nil, fmt.Errorf("before request hook failed: %w", err)
+	}
+
+	// the handshake must not outlive ctx, but the connection must
+	hsCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	req, err := http.NewRequestWithContext(hsCtx, http.MethodGet, muxReq.URL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if req, err = c.chooseProxy(muxReq, req); err != nil {
+		return nil, nil, err
+	}
+	for k, v := range muxReq.Headers {
+		req.Header.Set(k, v)
+	}
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := c.chain(upgradeDoer(c.client)).Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	muxResp := &Response{StatusCode: resp.StatusCode, Headers: resp.Header.Clone(), Raw: resp, RequestID: muxReq.ID}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if resp.StatusCode != http.StatusSwitchingProtocols || !ok ||
+		!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		muxResp.Body = body
+		return nil, muxResp, fmt.Errorf("%w: status %d", ErrBadHandshake, resp.StatusCode)
+	}
+	stop()
+	return &WebsocketConn{rwc: rwc, br: bufio.NewReader(rwc)}, muxResp, nil
+}
+
+// upgradeDoer returns d with HTTP/2 disabled, as the upgrade needs HTTP/1.1.
+// Transports other than *http.Transport are used as they are.
+func upgradeDoer(d HTTPDoer) HTTPDoer {
+	hc, ok := d.(*http.Client)
+	if !ok {
+		return d
+	}
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return d
+	}
+	t = t.Clone()
+	t.ForceAttemptHTTP2 = false
+	t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if t.TLSClientConfig != nil {
+		t.TLSClientConfig.NextProtos = nil
+	}
+	cp := *hc
+	cp.Transport = t
+	return &cp
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// ReadMessage returns the next text or binary message, answering pings on
+// the way. Once the peer closes the connection it returns a *CloseError.
+func (ws *WebsocketConn) ReadMessage() (int, []byte, error) {
+	var msgType int
+	var msg []byte
+	for {
+		fin, op, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			ce := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Text = string(payload[2:])
+			}
+			ws.closeWith(ce.Code)
+			return 0, nil, ce
+		case opContinuation:
+			if msgType == 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if msgType != 0 {
+				return 0, nil, errors.New("websocket: interleaved data frames")
+			}
+			msgType = int(op)
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", op)
+		}
+
+		if len(msg)+len(payload) > maxMessageSize {
+			return 0, nil, fmt.Errorf("websocket: message exceeds %d bytes", maxMessageSize)
+		}
+		msg = append(msg, payload...)
+		if fin {
+			if msgType == TextMessage && !utf8.Valid(msg) {
+				return 0, nil, errors.New("websocket: invalid UTF-8 in text message")
+			}
+			return msgType, msg, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single text or binary message
+func (ws *WebsocketConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return ws.writeFrame(byte(messageType), data)
+}
+
+// Ping sends a ping; the matching pong is consumed by ReadMessage
+func (ws *WebsocketConn) Ping(data []byte) error {
+	return ws.writeFrame(opPing, data)
+}
+
+// Close sends a normal closure to the peer and closes the connection
+func (ws *WebsocketConn) Close() error {
+	ws.closeWith(1000)
+	return ws.rwc.Close()
+}
+
+// closeWith sends a close frame unless one was sent already. 1005 means no
+// status was received and is never sent itself.
+func (ws *WebsocketConn) closeWith(code int) {
+	var payload []byte
+	if code != 1005 {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+	}
+	ws.writeFrame(opClose, payload)
+}
+
+func (ws *WebsocketConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	op = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxMessageSize {
+		return false, 0, nil, fmt.Errorf("websocket: frame exceeds %d bytes", maxMessageSize)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(ws.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(ws.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame sends a single final frame, masked as required for clients
+func (ws *WebsocketConn) writeFrame(op byte, payload []byte) error {
+	ws.wmu.Lock()
+	defer ws.wmu.Unlock()
+	if ws.closed {
+		return net.ErrClosed
+	}
+	if op == opClose {
+		ws.closed = true
+	}
+
+	frame := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	var mask [4]byte
+	rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	start := len(frame)
+	frame = append(frame, payload...)
+	maskBytes(mask, frame[start:])
+
+	_, err := ws.rwc.Write(frame)
+	return err
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
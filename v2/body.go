@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Body is a request body that encodes itself. A reader that can't seek is
+// sent only once, so the request isn't retried.
+type Body interface {
+	Encode() (contentType string, r io.Reader, err error)
+}
+
+// BodyFunc adapts a function to a Body
+type BodyFunc func() (contentType string, r io.Reader, err error)
+
+func (f BodyFunc) Encode() (string, io.Reader, error) {
+	return f()
+}
+
+// JSON encodes v as application/json
+func JSON(v any) Body {
+	return marshaled("application/json", json.Marshal, v)
+}
+
+// XML encodes v as application/xml
+func XML(v any) Body {
+	return marshaled("application/xml", xml.Marshal, v)
+}
+
+// Form sends form values as application/x-www-form-urlencoded
+func Form(values url.Values) Body {
+	return BodyFunc(func() (string, io.Reader, error) {
+		return "application/x-www-form-urlencoded", strings.NewReader(values.Encode()), nil
+	})
+}
+
+// Bytes sends data as it is
+func Bytes(contentType string, data []byte) Body {
+	return BodyFunc(func() (string, io.Reader, error) {
+		return contentType, bytes.NewReader(data), nil
+	})
+}
+
+// Reader streams r as it is
+func Reader(contentType string, r io.Reader) Body {
+	return BodyFunc(func() (string, io.Reader, error) {
+		return contentType, r, nil
+	})
+}
+
+// Encoded encodes v with the encoder the client registered for contentType,
+// e.g. one added by a package of codec
+func Encoded(contentType string, v any) Body {
+	return encoded{contentType: contentType, v: v}
+}
+
+type encoded struct {
+	contentType string
+	v           any
+}
+
+func (e encoded) Encode() (string, io.Reader, error) {
+	return "", nil, fmt.Errorf("body of type %s is encoded by the client", e.contentType)
+}
+
+func marshaled(contentType string, marshal func(any) ([]byte, error), v any) Body {
+	return BodyFunc(func() (string, io.Reader, error) {
+		data, err := marshal(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		return contentType, bytes.NewReader(data), nil
+	})
+}
+
+// encodeBody returns the body to pass to the v1 client and its Content-Type
+func encodeBody(body Body) (any, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case encoded:
+		return b.v, b.contentType, nil
+	}
+	contentType, r, err := body.Encode()
+	if err != nil {
+		return nil, "", err
+	}
+	return r, contentType, nil
+}
@@ -0,0 +1,100 @@
+// Package v2 is a preview of the next muxet API. Calls are context-first and
+// typed: each returns a Result[T] holding the decoded value and the response.
+// Bodies are Body values that choose their own encoding, everything that
+// varies between calls is a RequestOption, and a Client can't be changed once
+// built.
+//
+// It runs on the v1 engine, so v1 options, codecs and middleware work as they
+// are. To migrate gradually, Wrap an existing v1 client and move call sites
+// one at a time; V1 hands a client back to code that still needs the v1 API.
+package v2
+
+import (
+	"context"
+	"net/http"
+
+	muxet "github.com/Wizz-Tech/muxet/v1"
+)
+
+type (
+	// Option configures a Client in New, see muxet.Option
+	Option = muxet.Option
+	// RequestOption configures a single call, see muxet.RequestOption
+	RequestOption = muxet.RequestOption
+	Response      = muxet.Response
+	HTTPError     = muxet.HTTPError
+)
+
+// Client sends requests with a fixed configuration. It has no setters and is
+// safe for concurrent use.
+type Client struct {
+	c *muxet.Client
+}
+
+// New creates a client configured by opts, e.g.
+//
+//	c := v2.New(muxet.WithBaseURL(url), muxet.WithRetry(3, nil), muxet.WithTimeout(5*time.Second))
+func New(opts ...Option) *Client {
+	return &Client{c: muxet.NewClient(opts...)}
+}
+
+// Wrap returns a client with the configuration of a v1 client. c is cloned,
+// so changing it later doesn't affect the returned client.
+func Wrap(c *muxet.Client) *Client {
+	return &Client{c: c.Clone()}
+}
+
+// V1 returns a v1 client with the configuration of c, for code that hasn't
+// been migrated yet. Changing it doesn't affect c.
+func (c *Client) V1() *muxet.Client {
+	return c.c.Clone()
+}
+
+// Result is the outcome of a call: the decoded body and the response it came from
+type Result[T any] struct {
+	Value    T
+	Response *Response
+}
+
+// Do sends a request and decodes a successful response into a T with the
+// decoder registered for its Content-Type, JSON by default. An empty body
+// leaves Value zero. The Response is set for HTTP errors as well.
+func Do[T any](ctx context.Context, c *Client, method, url string, body Body, opts ...RequestOption) (Result[T], error) {
+	var res Result[T]
+	payload, contentType, err := encodeBody(body)
+	if err != nil {
+		return res, err
+	}
+	if contentType != "" {
+		opts = append([]RequestOption{muxet.WithHeader("Content-Type", contentType)}, opts...)
+	}
+	resp, err := c.c.R().SetContext(ctx).SetBody(payload).With(opts...).Execute(method, url)
+	res.Response = resp
+	if err != nil {
+		return res, err
+	}
+	if len(resp.Body) == 0 && resp.Stream == nil {
+		return res, nil
+	}
+	return res, c.c.Decode(resp, &res.Value)
+}
+
+func Get[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) (Result[T], error) {
+	return Do[T](ctx, c, http.MethodGet, url, nil, opts...)
+}
+
+func Post[T any](ctx context.Context, c *Client, url string, body Body, opts ...RequestOption) (Result[T], error) {
+	return Do[T](ctx, c, http.MethodPost, url, body, opts...)
+}
+
+func Put[T any](ctx context.Context, c *Client, url string, body Body, opts ...RequestOption) (Result[T], error) {
+	return Do[T](ctx, c, http.MethodPut, url, body, opts...)
+}
+
+func Patch[T any](ctx context.Context, c *Client, url string, body Body, opts ...RequestOption) (Result[T], error) {
+	return Do[T](ctx, c, http.MethodPatch, url, body, opts...)
+}
+
+func Delete[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) (Result[T], error) {
+	return Do[T](ctx, c, http.MethodDelete, url, nil, opts...)
+}